@@ -0,0 +1,75 @@
+package marketapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//AccountStatus - Сводка по аккаунту: Test, GetMoney, InventoryStatus, GetToken, количество ордеров
+//и предметов в Trades по статусам. Запросы выполняются параллельно с учетом лимита запросов на ключ.
+//Если какой-то запрос не удался, возвращается первая ошибка и сводка с заполненными остальными полями.
+//Все запросы выполняются с ctx, при его отмене они прерываются и возвращается ctx.Err().
+func (a *API) AccountStatus(ctx context.Context) (APIAccountStatus, error) {
+	api := a.WithContext(ctx)
+	var (
+		result   = APIAccountStatus{Time: time.Now(), Trades: map[string]int{}}
+		resultMu sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	run := func(call func() (func(), error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			apply, err := call()
+			resultMu.Lock()
+			defer resultMu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			apply()
+		}()
+	}
+
+	run(func() (func(), error) {
+		test, err := api.Test()
+		return func() { result.Status = test.Status }, err
+	})
+	run(func() (func(), error) {
+		money, err := api.GetMoney()
+		return func() { result.Money = money.Money }, err
+	})
+	run(func() (func(), error) {
+		inventory, err := api.InventoryStatus()
+		return func() {
+			result.InventoryStatus = inventory.IStatus
+			result.InventoryTime = inventory.ITime
+		}, err
+	})
+	run(func() (func(), error) {
+		token, err := api.GetToken()
+		return func() { result.Token = token.Token }, err
+	})
+	run(func() (func(), error) {
+		orders, err := api.GetOrders()
+		return func() { result.Orders = len(orders.Orders) }, err
+	})
+	run(func() (func(), error) {
+		trades, err := api.Trades()
+		return func() {
+			for _, trade := range trades {
+				result.Trades[trade.UIStatus]++
+			}
+		}, err
+	})
+
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return APIAccountStatus{}, err
+	}
+	return result, firstErr
+}
@@ -0,0 +1,114 @@
+package marketapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+//AlertTarget - желаемое уведомление: сообщить, если предмет продается дешевле Price (в копейках).
+type AlertTarget struct {
+	ClassID    string
+	InstanceID string
+	Price      int64
+}
+
+//AlertChange - изменение уведомления, выполненное AlertSync.
+type AlertChange struct {
+	ClassID    string
+	InstanceID string
+	OldPrice   int64 // 0 - уведомления не было
+	NewPrice   int64 // 0 - уведомление удалено
+	Err        error
+}
+
+//AlertSync - приводит уведомления маркета о изменении цены к списку Targets.
+//Если задан OnTrigger, Run также сам проверяет предложения из ItemInfo и вызывает OnTrigger,
+//когда самое дешевое предложение ниже порога.
+type AlertSync struct {
+	API       *API
+	Targets   []AlertTarget
+	Interval  time.Duration // по умолчанию 1 минута
+	OnTrigger func(target AlertTarget, price int64)
+	OnError   func(err error)
+}
+
+//Reconcile - добавляет, изменяет и удаляет (цена 0) уведомления так, чтобы они совпадали с Targets.
+func (s *AlertSync) Reconcile() ([]AlertChange, error) {
+	notifications, err := s.API.GetNotifications()
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]int64{}
+	for _, n := range notifications.Notifications {
+		price, _ := strconv.ParseInt(n.NVal, 10, 64)
+		current[orderKey(n.IClassid, n.IInstanceid)] = price
+	}
+
+	var changes []AlertChange
+	wanted := map[string]bool{}
+	for _, target := range s.Targets {
+		key := orderKey(target.ClassID, target.InstanceID)
+		wanted[key] = true
+		if old := current[key]; old != target.Price {
+			change := AlertChange{
+				ClassID:    target.ClassID,
+				InstanceID: target.InstanceID,
+				OldPrice:   old,
+				NewPrice:   target.Price,
+			}
+			_, change.Err = s.API.UpdateNotification(target.ClassID, target.InstanceID, target.Price)
+			changes = append(changes, change)
+		}
+	}
+	for _, n := range notifications.Notifications {
+		key := orderKey(n.IClassid, n.IInstanceid)
+		if wanted[key] {
+			continue
+		}
+		change := AlertChange{
+			ClassID:    n.IClassid,
+			InstanceID: n.IInstanceid,
+			OldPrice:   current[key],
+		}
+		_, change.Err = s.API.UpdateNotification(n.IClassid, n.IInstanceid, 0)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+//Watch - проверяет предложения ItemInfo для всех Targets и вызывает OnTrigger для сработавших.
+func (s *AlertSync) Watch() error {
+	for _, target := range s.Targets {
+		info, err := s.API.ItemInfo(target.ClassID, target.InstanceID)
+		if err != nil {
+			return err
+		}
+		var lowest int64
+		for _, offer := range info.Offers {
+			price, err := strconv.ParseInt(offer.Price, 10, 64)
+			if err == nil && (lowest == 0 || price < lowest) {
+				lowest = price
+			}
+		}
+		if lowest != 0 && lowest < target.Price && s.OnTrigger != nil {
+			s.OnTrigger(target, lowest)
+		}
+	}
+	return nil
+}
+
+//Run - выполняет Reconcile, а затем, если задан OnTrigger, вызывает Watch каждые Interval до отмены ctx.
+func (s *AlertSync) Run(ctx context.Context) error {
+	if _, err := s.Reconcile(); err != nil {
+		return err
+	}
+	if s.OnTrigger == nil {
+		return nil
+	}
+	return runEvery(ctx, s.Interval, time.Minute, func() {
+		if err := s.Watch(); err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package marketapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strconv"
+)
+
+//PriceSource - внешний источник цен (например, Steam Community Market). Цена в копейках.
+type PriceSource interface {
+	Price(action string, marketName string) (int64, bool)
+}
+
+//MapPriceSource - цены по названию предмета, одинаковые для всех игр.
+type MapPriceSource map[string]int64
+
+func (m MapPriceSource) Price(action string, marketName string) (int64, bool) {
+	price, ok := m[marketName]
+	return price, ok
+}
+
+//LoadPriceFile - читает JSON файл вида {"AK-47 | Redline (Field-Tested)": 1234, ...} с ценами в копейках.
+func LoadPriceFile(path string) (MapPriceSource, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var prices MapPriceSource
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+const (
+	ArbitrageBuyHere  = "buy-here"  // купить на маркете, продать во внешнем источнике
+	ArbitrageSellHere = "sell-here" // купить во внешнем источнике, продать на маркете
+)
+
+//Opportunity - найденная возможность арбитража. Profit - прибыль в копейках после комиссий.
+type Opportunity struct {
+	Action      string
+	Line        CsvLine
+	MarketPrice int64
+	RefPrice    int64
+	Direction   string
+	Profit      int64
+	ROI         float64
+}
+
+//ArbitrageScanner - сравнивает ItemDB всех APIs с ценами из Source.
+//MarketFee и RefFee - комиссии при продаже на маркете и во внешнем источнике (0.05 = 5%).
+//Предметы с COffers < MinOffers или CPopularity < MinPopularity пропускаются.
+type ArbitrageScanner struct {
+	APIs          []*API
+	Source        PriceSource
+	MarketFee     float64
+	RefFee        float64
+	MinOffers     int64
+	MinPopularity int64
+	MinProfit     int64
+}
+
+func (s *ArbitrageScanner) check(action string, line CsvLine) []Opportunity {
+	offers, _ := strconv.ParseInt(line.COffers, 10, 64)
+	popularity, _ := strconv.ParseInt(line.CPopularity, 10, 64)
+	if offers < s.MinOffers || popularity < s.MinPopularity {
+		return nil
+	}
+	price, err := strconv.ParseInt(line.CPrice, 10, 64)
+	if err != nil || price <= 0 {
+		return nil
+	}
+	ref, ok := s.Source.Price(action, line.CMarketName)
+	if !ok || ref <= 0 {
+		return nil
+	}
+
+	var result []Opportunity
+	add := func(direction string, cost int64, profit int64) {
+		if profit > 0 && profit >= s.MinProfit {
+			result = append(result, Opportunity{
+				Action:      action,
+				Line:        line,
+				MarketPrice: price,
+				RefPrice:    ref,
+				Direction:   direction,
+				Profit:      profit,
+				ROI:         float64(profit) / float64(cost),
+			})
+		}
+	}
+	add(ArbitrageBuyHere, price, int64(float64(ref)*(1-s.RefFee))-price)
+	add(ArbitrageSellHere, ref, int64(float64(price)*(1-s.MarketFee))-ref)
+	return result
+}
+
+//Scan - загружает ItemDB каждого API и возвращает возможности, отсортированные по убыванию прибыли.
+func (s *ArbitrageScanner) Scan() ([]Opportunity, error) {
+	var result []Opportunity
+	for _, api := range s.APIs {
+		lines, err := api.CurrentItemDB()
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			result = append(result, s.check(api.Action, line)...)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Profit > result[j].Profit })
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package marketapi
+
+import (
+	"context"
+	"sync"
+)
+
+//BatchWorkers - число одновременных запросов в ItemInfoBatch.
+//Реальная частота все равно ограничена RateInterval.
+const BatchWorkers = 5
+
+//ItemInfoBatch - ItemInfo для всех ids. Повторяющиеся ids запрашиваются один раз.
+//Результаты приходят в канал по мере готовности, ошибка у каждого предмета своя.
+//Канал закрывается, когда все предметы обработаны или отменен ctx.
+func (a *API) ItemInfoBatch(ctx context.Context, ids []ItemID) <-chan ItemInfoResult {
+	jobs := make(chan ItemID)
+	results := make(chan ItemInfoResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < BatchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				info, err := a.itemInfo(ctx, id.ClassID, id.InstanceID)
+				select {
+				case results <- ItemInfoResult{ID: id, Info: info, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		seen := map[ItemID]bool{}
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			select {
+			case jobs <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
@@ -0,0 +1,81 @@
+package marketapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+//BuyByName - Покупка самого дешевого предмета с market_hash_name = hashName (например "AK-47 | Redline (Field-Tested)")
+//по цене не выше maxPrice (в копейках). Кандидаты ищутся в ItemDB (c_market_name в ItemDB - market_hash_name),
+//затем сверяются с MarketHashName из ItemInfo, которое не зависит от Lang. Предложения и hash берутся из ItemInfo.
+//Hash передается в Buy, поэтому если описание предмета изменилось, маркет откажет в покупке.
+func (a *API) BuyByName(hashName string, maxPrice int64) (APIBuyByName, error) {
+	return a.BuyByNameContext(a.context(), hashName, maxPrice)
+}
+
+//BuyByNameContext - BuyByName, все запросы которого выполняются с ctx внутри спана marketapi.BuyByName.
+func (a *API) BuyByNameContext(ctx context.Context, hashName string, maxPrice int64) (result APIBuyByName, err error) {
+	ctx, span := a.startSpan(ctx, "marketapi.BuyByName", "market_hash_name", hashName, "max_price", maxPrice)
+	defer func() {
+		span.SetAttribute("classid", result.ClassID)
+		span.SetAttribute("instanceid", result.InstanceID)
+		span.SetAttribute("price", result.Price)
+		span.SetAttribute("id", result.ID)
+		endSpan(span, err)
+	}()
+	return a.WithContext(ctx).buyByName(hashName, maxPrice)
+}
+
+func (a *API) buyByName(hashName string, maxPrice int64) (APIBuyByName, error) {
+	lines, err := a.CurrentItemDB()
+	if err != nil {
+		return APIBuyByName{}, err
+	}
+
+	var best APIBuyByName
+	found := false
+	for _, line := range lines {
+		if line.CMarketName != hashName {
+			continue
+		}
+		found = true
+		info, err := a.ItemInfo(line.CClassID, line.CInstanceID)
+		if err != nil {
+			return APIBuyByName{}, err
+		}
+		if info.MarketHashName != "" && info.MarketHashName != hashName {
+			continue
+		}
+		for _, offer := range info.Offers {
+			price, err := strconv.ParseInt(offer.Price, 10, 64)
+			if err != nil || price > maxPrice {
+				continue
+			}
+			if best.ClassID == "" || price < best.Price {
+				best = APIBuyByName{
+					ClassID:    line.CClassID,
+					InstanceID: line.CInstanceID,
+					Hash:       info.Hash,
+					Price:      price,
+				}
+			}
+		}
+	}
+	if !found {
+		return APIBuyByName{}, errors.New(ErrAPINotFound)
+	}
+	if best.ClassID == "" {
+		return APIBuyByName{}, errors.New(ErrAPINoOffers)
+	}
+	if best.Hash == "" {
+		return APIBuyByName{}, errors.New(ErrAPINoHash)
+	}
+
+	bought, err := a.Buy(best.ClassID, best.InstanceID, best.Price, best.Hash)
+	if err != nil {
+		return APIBuyByName{}, err
+	}
+	best.ID = bought.ID
+	return best, nil
+}
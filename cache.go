@@ -0,0 +1,243 @@
+package marketapi
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//CacheBackend - хранилище кэша. Интерфейс повторяет GET/SET EX/DEL из Redis,
+//так что к нему легко подключить любой Redis клиент; MemoryCache - локальная реализация.
+type CacheBackend interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Del(keys ...string) error
+}
+
+//Cache - кэш ответов для методов, только читающих данные.
+//TTL - время жизни по имени метода ("ItemInfo", "ItemHistory", "ItemDBCurrent"), методы без TTL не кэшируются.
+//Одновременные одинаковые запросы объединяются в один.
+type Cache struct {
+	Backend CacheBackend
+	TTL     map[string]time.Duration
+
+	mutex sync.Mutex
+	calls map[string]*cacheCall
+	items map[string][2]string // item_id из Trades -> classid, instanceid
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	bytes []byte
+	err   error
+}
+
+//NewCache - кэш с TTL по умолчанию: ItemInfo 5 секунд, ItemHistory 30 секунд, ItemDBCurrent 1 минута.
+//Если backend - nil, используется MemoryCache.
+func NewCache(backend CacheBackend) *Cache {
+	if backend == nil {
+		backend = &MemoryCache{}
+	}
+	return &Cache{
+		Backend: backend,
+		TTL: map[string]time.Duration{
+			"ItemInfo":      5 * time.Second,
+			"ItemHistory":   30 * time.Second,
+			"ItemDBCurrent": time.Minute,
+		},
+	}
+}
+
+//backend - Backend или MemoryCache, если он не задан.
+func (c *Cache) backend() CacheBackend {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.Backend == nil {
+		c.Backend = &MemoryCache{}
+	}
+	return c.Backend
+}
+
+func cacheKey(endpoint string, url string) string {
+	sum := sha1.Sum([]byte(url))
+	return endpoint + ":" + hex.EncodeToString(sum[:])
+}
+
+//cachedGet - makeGetContext через кэш a.Cache, если он задан и для endpoint есть TTL.
+func (a *API) cachedGet(ctx context.Context, endpoint string, url string, attrs ...interface{}) ([]byte, error) {
+	c := a.Cache
+	if c == nil || c.TTL[endpoint] == 0 {
+		return a.makeGetContext(ctx, url, attrs...)
+	}
+	backend := c.backend()
+	key := cacheKey(endpoint, url)
+	if bytes, ok, err := backend.Get(key); err == nil && ok {
+		return bytes, nil
+	}
+
+	for {
+		c.mutex.Lock()
+		call, ok := c.calls[key]
+		if !ok {
+			break
+		}
+		c.mutex.Unlock()
+		select {
+		case <-ctx.Done():
+			return []byte{}, ctx.Err()
+		case <-call.done:
+		}
+		//отмена контекста первого запроса - не ошибка остальных, они повторяют запрос сами
+		if call.err != context.Canceled && call.err != context.DeadlineExceeded {
+			return call.bytes, call.err
+		}
+	}
+	if c.calls == nil {
+		c.calls = map[string]*cacheCall{}
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mutex.Unlock()
+
+	call.bytes, call.err = a.makeGetContext(ctx, url, attrs...)
+	if call.err == nil {
+		backend.Set(key, call.bytes, c.TTL[endpoint])
+	}
+
+	c.mutex.Lock()
+	delete(c.calls, key)
+	c.mutex.Unlock()
+	close(call.done)
+	return call.bytes, call.err
+}
+
+//rememberItems - запоминает classid и instanceid предметов из последнего ответа Trades для InvalidateItemID.
+//Предметы, которых больше нет в Trades, забываются.
+func (c *Cache) rememberItems(trades APITrades) {
+	items := make(map[string][2]string, len(trades))
+	for _, trade := range trades {
+		items[trade.UIID] = [2]string{trade.IClassID, trade.IInstanceID}
+	}
+	c.mutex.Lock()
+	c.items = items
+	c.mutex.Unlock()
+}
+
+func (c *Cache) item(itemid string) ([2]string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	item, ok := c.items[itemid]
+	return item, ok
+}
+
+//InvalidateItem - удаляет из кэша ItemInfo и ItemHistory предмета.
+//Вызывается автоматически после Buy, SetPrice и SetPriceNew.
+func (a *API) InvalidateItem(classid string, instanceid string) error {
+	if a.Cache == nil {
+		return nil
+	}
+	return a.Cache.backend().Del(
+		cacheKey("ItemInfo", fmt.Sprintf(URLItemInfo, a.URL, classid, instanceid, a.Lang, a.Key.Reveal())),
+		cacheKey("ItemHistory", fmt.Sprintf(URLItemHistory, a.URL, classid, instanceid, a.Key.Reveal())),
+	)
+}
+
+//invalidateItemID - InvalidateItem для item_id со страницы "Мои вещи". Если предмет еще не встречался в Trades,
+//classid и instanceid берутся из нового запроса Trades.
+func (a *API) invalidateItemID(itemid string) error {
+	if a.Cache == nil {
+		return nil
+	}
+	item, ok := a.Cache.item(itemid)
+	if !ok {
+		if _, err := a.Trades(); err != nil {
+			return err
+		}
+		if item, ok = a.Cache.item(itemid); !ok {
+			return nil
+		}
+	}
+	return a.InvalidateItem(item[0], item[1])
+}
+
+//MemoryCache - LRU кэш в памяти на Size записей (по умолчанию 10000).
+type MemoryCache struct {
+	Size int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func (m *MemoryCache) init() {
+	if m.entries == nil {
+		m.entries = map[string]*list.Element{}
+		m.order = list.New()
+	}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.init()
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.order.Remove(element)
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.init()
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expires = time.Now().Add(ttl)
+		m.order.MoveToFront(element)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+
+	size := m.Size
+	if size == 0 {
+		size = 10000
+	}
+	for m.order.Len() > size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+func (m *MemoryCache) Del(keys ...string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.init()
+	for _, key := range keys {
+		if element, ok := m.entries[key]; ok {
+			m.order.Remove(element)
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package marketapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//blockingItemInfo - сервер, который отвечает на ItemInfo только после закрытия release.
+func blockingItemInfo(hits *int32, started chan<- struct{}, release <-chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/ItemInfo/") {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(hits, 1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(`{"classid":"1","instanceid":"0","market_hash_name":"Item"}`))
+	}))
+}
+
+func TestCacheCoalescesRequests(t *testing.T) {
+	var hits int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := blockingItemInfo(&hits, started, release)
+	defer server.Close()
+
+	api := &API{Key: "test-cache-coalesce", URL: server.URL, RateInterval: time.Millisecond, Cache: NewCache(nil)}
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := api.ItemInfo("1", "0")
+			errs <- err
+		}()
+	}
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Fatalf("requests = %d, want 1", got)
+	}
+	if _, err := api.ItemInfo("1", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Fatalf("requests after cached ItemInfo = %d, want 1", got)
+	}
+}
+
+func TestCacheWaiterCancel(t *testing.T) {
+	var hits int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := blockingItemInfo(&hits, started, release)
+	defer server.Close()
+
+	api := &API{Key: "test-cache-cancel", URL: server.URL, RateInterval: time.Millisecond, Cache: &Cache{
+		TTL: map[string]time.Duration{"ItemInfo": time.Minute},
+	}}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := api.WithContext(leaderCtx).ItemInfo("1", "0")
+		leader <- err
+	}()
+	<-started
+
+	//отмена ожидающего запроса не ждет первого
+	waiterCtx, cancelWaiter := context.WithCancel(context.Background())
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := api.WithContext(waiterCtx).ItemInfo("1", "0")
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelWaiter()
+	select {
+	case err := <-waiter:
+		if err != context.Canceled {
+			t.Fatalf("waiter err = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("canceled waiter blocked on the leader request")
+	}
+
+	//отмена первого запроса не передается остальным, они повторяют запрос сами
+	other := make(chan error, 1)
+	go func() {
+		_, err := api.ItemInfo("1", "0")
+		other <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelLeader()
+	if err := <-leader; err == nil {
+		t.Fatal("leader err = nil, want cancellation error")
+	}
+	close(release)
+	select {
+	case err := <-other:
+		if err != nil {
+			t.Fatalf("waiter err = %v after leader was canceled, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter did not retry after leader was canceled")
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+}
@@ -0,0 +1,451 @@
+//marketctl - консольный клиент API маркета.
+//
+//	marketctl [flags] <game> <command> [args...]
+//
+//game - dota2, csgo, tf2 или gifts. Ключ берется из -key, переменной MARKETAPI_KEY
+//или из файла аккаунтов -config (см. marketapi.LoadAccountPool) по имени -account, игра аккаунта должна совпадать с game.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/soluchok/marketapi"
+	"github.com/soluchok/marketapi/export"
+)
+
+type command struct {
+	usage  string
+	mutate bool // при -dry-run не выполняется
+	run    func(api *marketapi.API, args []string) (interface{}, error)
+}
+
+var errUsage = errors.New("wrong arguments")
+
+func splitID(id string) (string, string, error) {
+	parts := strings.SplitN(id, "_", 2)
+	if len(parts) != 2 {
+		return "", "", errUsage
+	}
+	return parts[0], parts[1], nil
+}
+
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errUsage
+	}
+	return n, nil
+}
+
+//parsePrice - цена или сумма в копейках.
+func parsePrice(s string) (int64, error) {
+	return parseInt(s)
+}
+
+func arg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+var commands = map[string]command{
+	"ping": {"ping", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.PingPong()
+	}},
+	"test": {"test", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.Test()
+	}},
+	"money": {"money", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.GetMoney()
+	}},
+	"itemdb": {"itemdb", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.CurrentItemDB()
+	}},
+	"item-info": {"item-info <classid_instanceid>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		classid, instanceid, err := splitID(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		return a.ItemInfo(classid, instanceid)
+	}},
+	"item-history": {"item-history <classid_instanceid>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		classid, instanceid, err := splitID(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		return a.ItemHistory(classid, instanceid)
+	}},
+	"trades": {"trades", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.Trades()
+	}},
+	"market-trades": {"market-trades", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.MarketTrades()
+	}},
+	"buy": {"buy <classid_instanceid> <price> [hash]", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		classid, instanceid, err := splitID(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		price, err := parsePrice(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return a.Buy(classid, instanceid, price, arg(args, 2))
+	}},
+	"buy-by-name": {"buy-by-name <market_hash_name> <max_price>", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		price, err := parsePrice(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return a.BuyByName(arg(args, 0), price)
+	}},
+	"set-price": {"set-price <item_id|new_classid_instanceid> <price>", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		price, err := parsePrice(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		if id := arg(args, 0); strings.HasPrefix(id, "new_") {
+			classid, instanceid, err := splitID(strings.TrimPrefix(id, "new_"))
+			if err != nil {
+				return nil, err
+			}
+			return a.SetPriceNew(classid, instanceid, price)
+		}
+		return a.SetPrice(arg(args, 0), price)
+	}},
+	"remove-all": {"remove-all", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.RemoveAll()
+	}},
+	"item-request": {"item-request <in|out> <botid>", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		dir := marketapi.ItemRequestDir(arg(args, 0))
+		if dir != marketapi.ItemRequestIn && dir != marketapi.ItemRequestOut {
+			return nil, errUsage
+		}
+		return a.ItemRequest(dir, arg(args, 1))
+	}},
+	"history": {"history <start_unix> <end_unix>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		start, err := parseInt(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseInt(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return a.OperationHistory(start, end)
+	}},
+	"inventory-status": {"inventory-status", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.InventoryStatus()
+	}},
+	"update-inventory": {"update-inventory", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.UpdateInventory()
+	}},
+	"token": {"token get | token set <token|trade_url>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		switch arg(args, 0) {
+		case "get":
+			return a.GetToken()
+		case "set":
+			token := arg(args, 1)
+			if u, err := marketapi.ParseTradeURL(token); err == nil {
+				token = u.Token
+			}
+			return a.SetToken(token)
+		}
+		return nil, errUsage
+	}},
+	"quick-items": {"quick-items", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.QuickItems()
+	}},
+	"quick-buy": {"quick-buy <ui_id>", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.QuickBuy(arg(args, 0))
+	}},
+	"orders": {"orders list | insert <classid_instanceid> <price> [hash] | update <classid_instanceid> <price> | delete", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		switch arg(args, 0) {
+		case "list":
+			return a.GetOrders()
+		case "delete":
+			return a.DeleteOrders()
+		case "insert", "update":
+			classid, instanceid, err := splitID(arg(args, 1))
+			if err != nil {
+				return nil, err
+			}
+			price, err := parsePrice(arg(args, 2))
+			if err != nil {
+				return nil, err
+			}
+			if arg(args, 0) == "insert" {
+				return a.InsertOrder(classid, instanceid, price, arg(args, 3))
+			}
+			return a.UpdateOrder(classid, instanceid, price)
+		}
+		return nil, errUsage
+	}},
+	"notifications": {"notifications list | update <classid_instanceid> <price>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		switch arg(args, 0) {
+		case "list":
+			return a.GetNotifications()
+		case "update":
+			classid, instanceid, err := splitID(arg(args, 1))
+			if err != nil {
+				return nil, err
+			}
+			price, err := parsePrice(arg(args, 2))
+			if err != nil {
+				return nil, err
+			}
+			return a.UpdateNotification(classid, instanceid, price)
+		}
+		return nil, errUsage
+	}},
+	"ws-auth": {"ws-auth", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.GetWSAuth()
+	}},
+	"v2-items": {"v2-items", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.V2().Items()
+	}},
+	"v2-search": {"v2-search <market_hash_name>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.V2().SearchItemByHashName(arg(args, 0))
+	}},
+	"v2-prices": {"v2-prices <RUB|USD|EUR>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.V2().Prices(arg(args, 0))
+	}},
+	"v2-prices-class-instance": {"v2-prices-class-instance <RUB|USD|EUR>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.V2().PricesClassInstance(arg(args, 0))
+	}},
+	"v2-history": {"v2-history <start_unix> <end_unix>", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		start, err := parseInt(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseInt(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return a.V2().History(start, end)
+	}},
+	"v2-add-to-sale": {"v2-add-to-sale <asset_id> <price> <RUB|USD|EUR>", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		price, err := parsePrice(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return a.V2().AddToSale(arg(args, 0), price, arg(args, 2))
+	}},
+	"v2-set-price": {"v2-set-price <item_id> <price> <RUB|USD|EUR>", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		price, err := parsePrice(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return a.V2().SetPrice(arg(args, 0), price, arg(args, 2))
+	}},
+	"v2-buy": {"v2-buy <market_hash_name> <max_price>", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		price, err := parsePrice(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return a.V2().Buy(arg(args, 0), price)
+	}},
+	"v2-trade-request-give-p2p": {"v2-trade-request-give-p2p", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		return a.V2().TradeRequestGiveP2P()
+	}},
+	"v2-money-send": {"v2-money-send <amount> <recipient_key> (pay password from $MARKETAPI_PAY_PASS)", true, func(a *marketapi.API, args []string) (interface{}, error) {
+		amount, err := parsePrice(arg(args, 0))
+		if err != nil || arg(args, 1) == "" {
+			return nil, errUsage
+		}
+		return a.V2().MoneySend(amount, arg(args, 1), os.Getenv("MARKETAPI_PAY_PASS"))
+	}},
+	"export": {"export <csv|jsonl|parquet> <file> (parquet needs -tags parquet)", false, func(a *marketapi.API, args []string) (interface{}, error) {
+		write, ok := exporters[arg(args, 0)]
+		if !ok || arg(args, 1) == "" {
+			return nil, errUsage
+		}
+		s, err := export.Load(a)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Create(arg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		if err := write(f, s); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"file": arg(args, 1), "snapshot": s.Time, "lines": len(s.Lines)}, nil
+	}},
+}
+
+//exporters - форматы команды export, parquet добавляется при сборке с тегом parquet.
+var exporters = map[string]func(w io.Writer, s export.Snapshot) error{
+	"csv":   export.WriteCSV,
+	"jsonl": export.WriteJSONL,
+}
+
+//mutating - меняет ли команда состояние аккаунта.
+func mutating(name string, cmd command, args []string) bool {
+	switch name {
+	case "token":
+		return arg(args, 0) == "set"
+	case "orders":
+		return arg(args, 0) != "list"
+	case "notifications":
+		return arg(args, 0) == "update"
+	}
+	return cmd.mutate
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: marketctl [flags] <dota2|csgo|tf2|gifts> <command> [args...]\n\nflags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "marketctl:", err)
+	os.Exit(1)
+}
+
+func main() {
+	key := flag.String("key", "", "API key (default $MARKETAPI_KEY)")
+	config := flag.String("config", "", "accounts file, see marketapi.LoadAccountPool")
+	account := flag.String("account", "", "account name in -config")
+	format := flag.String("format", "json", "output format: json or table")
+	dryRun := flag.Bool("dry-run", false, "print mutating commands instead of running them")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		usage()
+		os.Exit(2)
+	}
+	game, name, args := flag.Arg(0), flag.Arg(1), flag.Args()[2:]
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	var api *marketapi.API
+	if *config != "" {
+		pool, err := marketapi.LoadAccountPool(*config)
+		if err != nil {
+			fail(err)
+		}
+		if api, err = pool.Get(*account); err != nil {
+			fail(err)
+		}
+		action, err := marketapi.GameAction(game)
+		if err != nil {
+			fail(err)
+		}
+		if api.Action != action {
+			fail(fmt.Errorf("account %q is not a %s account", *account, game))
+		}
+	} else {
+		pool := marketapi.NewAccountPool()
+		accountConfig := marketapi.AccountConfig{Name: "cli", Game: game, Key: *key}
+		if *key == "" {
+			accountConfig.KeyEnv = "MARKETAPI_KEY"
+		}
+		if err := pool.AddConfig(accountConfig); err != nil {
+			fail(err)
+		}
+		api, _ = pool.Get("cli")
+	}
+
+	if *dryRun && mutating(name, cmd, args) {
+		shown := append([]string(nil), args...)
+		if name == "v2-money-send" && len(shown) > 1 {
+			shown[1] = marketapi.Key(shown[1]).String()
+		}
+		fmt.Printf("dry run: %s %s %s\n", game, name, strings.Join(shown, " "))
+		return
+	}
+
+	result, err := cmd.run(api, args)
+	if err == errUsage {
+		fmt.Fprintf(os.Stderr, "usage: marketctl %s %s\n", game, cmd.usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+	if *format == "table" {
+		printTable(result)
+		return
+	}
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println(string(out))
+}
+
+//printTable - срез структур печатается таблицей, структура - парами поле, значение.
+//Если в структуре есть срез структур (например, Orders), печатается он.
+func printTable(result interface{}) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+	v := reflect.Indirect(reflect.ValueOf(result))
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Struct && f.Len() > 0 {
+				v = f
+				break
+			}
+		}
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct {
+		t := v.Type().Elem()
+		var header []string
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" {
+				header = append(header, t.Field(i).Name)
+			}
+		}
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for i := 0; i < v.Len(); i++ {
+			var row []string
+			for j := 0; j < t.NumField(); j++ {
+				if t.Field(j).PkgPath == "" {
+					row = append(row, fmt.Sprint(v.Index(i).Field(j).Interface()))
+				}
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return
+	}
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				fmt.Fprintf(w, "%s\t%v\n", v.Type().Field(i).Name, v.Field(i).Interface())
+			}
+		}
+		return
+	}
+	fmt.Fprintln(w, result)
+}
@@ -0,0 +1,9 @@
+//go:build parquet
+
+package main
+
+import "github.com/soluchok/marketapi/export/parquet"
+
+func init() {
+	exporters["parquet"] = parquet.Write
+}
@@ -2,7 +2,29 @@ package marketapi
 
 const (
 	ErrAPITimeout   = "timeout"
+	ErrAPIServer    = "market server error"
 	ErrAPIMinAmount = "amount must be at least 100"
+	ErrAPINotFound  = "item not found"
+	ErrAPINoOffers  = "no offers below max price"
+	ErrAPINoHash    = "item hash is empty"
+	ErrAPIBadBotID  = "bot id must be a positive number"
+	ErrAPINoTradeID = "no trade offer id in response"
+	ErrAPIInventory = "inventory update failed"
+	ErrAPIBadToken  = "invalid trade token"
+	ErrAPIBadURL    = "invalid trade url"
+	ErrAPIBadGame   = "game must be one of dota2, csgo, tf2, gifts"
+	ErrAPINoAccount = "account not found"
+	ErrAPINoKey     = "api key is empty"
+	ErrAPIBadDir    = "item request direction must be in or out"
+	ErrAPIBadEnv    = "account variable must be <prefix>ACCOUNT_<name>=<game>:<key>"
+)
+
+//ItemRequestDir - направление ItemRequest.
+type ItemRequestDir string
+
+const (
+	ItemRequestIn  ItemRequestDir = "in"  // передать проданные предметы боту, botid = "1"
+	ItemRequestOut ItemRequestDir = "out" // забрать купленные предметы у бота, botid = Trade.UIBid
 )
 
 const (
@@ -19,6 +41,13 @@ const (
 	CodeGIFTS = "753"
 )
 
+var games = map[string]struct{ action, url, code string }{
+	"dota2": {ActDOTA2, URLDota2, CodeDOTA2},
+	"csgo":  {ActCSGO, URLCsgo, CodeCSGO},
+	"tf2":   {ActTF2, URLTf2, CodeTF2},
+	"gifts": {ActGIFTS, URLGifts, CodeGIFTS},
+}
+
 const (
 	URLDota2 = "https://market.dota2.net"
 	URLCsgo  = "https://market.csgo.com"
@@ -56,3 +85,22 @@ const (
 	URLUpdateNotification = "%s/api/UpdateNotification/%s/%s/%d/?key=%s"
 	URLGetWSAuth          = "%s/api/GetWSAuth/?key=%s"
 )
+
+const (
+	URLV2Items                = "%s/api/v2/items?key=%s"
+	URLV2AddToSale            = "%s/api/v2/add-to-sale?key=%s&id=%s&price=%d&cur=%s"
+	URLV2SetPrice             = "%s/api/v2/set-price?key=%s&item_id=%s&price=%d&cur=%s"
+	URLV2Buy                  = "%s/api/v2/buy?key=%s&hash_name=%s&price=%d"
+	URLV2SearchItemByHashName = "%s/api/v2/search-item-by-hash-name?key=%s&hash_name=%s"
+	URLV2Prices               = "%s/api/v2/prices/%s.json"
+	URLV2PricesClassInstance  = "%s/api/v2/prices/class_instance/%s.json"
+	URLV2TradeRequestGiveP2P  = "%s/api/v2/trade-request-give-p2p?key=%s"
+	URLV2History              = "%s/api/v2/history?key=%s&date=%d&date_end=%d"
+	URLV2MoneySend            = "%s/api/v2/money-send/%d/%s?pay_pass=%s&key=%s"
+)
+
+const (
+	CurRUB = "RUB"
+	CurUSD = "USD"
+	CurEUR = "EUR"
+)
@@ -0,0 +1,157 @@
+//Package export - выгрузка ItemDB в CSV (через запятую) и JSON Lines, Parquet - в подпакете export/parquet.
+//Колонки зависят от игры и повторяют порядок колонок ItemDB, цены и счетчики приводятся к числам.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/soluchok/marketapi"
+)
+
+//Column - колонка выгрузки. Int - значение приводится к int64, пустое или нечисловое значение - null.
+type Column struct {
+	Name  string
+	Int   bool
+	Value func(line marketapi.CsvLine) string
+}
+
+var (
+	colClassID      = Column{"class_id", false, func(l marketapi.CsvLine) string { return l.CClassID }}
+	colInstanceID   = Column{"instance_id", false, func(l marketapi.CsvLine) string { return l.CInstanceID }}
+	colPrice        = Column{"price", true, func(l marketapi.CsvLine) string { return l.CPrice }}
+	colOffers       = Column{"offers", true, func(l marketapi.CsvLine) string { return l.COffers }}
+	colPopularity   = Column{"popularity", true, func(l marketapi.CsvLine) string { return l.CPopularity }}
+	colRarity       = Column{"rarity", false, func(l marketapi.CsvLine) string { return l.CRarity }}
+	colQuality      = Column{"quality", false, func(l marketapi.CsvLine) string { return l.CQuality }}
+	colHeroID       = Column{"hero_id", false, func(l marketapi.CsvLine) string { return l.CHeroID }}
+	colSlot         = Column{"slot", false, func(l marketapi.CsvLine) string { return l.CSlot }}
+	colStickers     = Column{"stickers", false, func(l marketapi.CsvLine) string { return l.CStickers }}
+	colOs           = Column{"os", false, func(l marketapi.CsvLine) string { return l.COs }}
+	colFeatures     = Column{"features", false, func(l marketapi.CsvLine) string { return l.CFeatures }}
+	colRating       = Column{"rating", false, func(l marketapi.CsvLine) string { return l.CRating }}
+	colCraftable    = Column{"craftable", false, func(l marketapi.CsvLine) string { return l.CCraftable }}
+	colLook         = Column{"look", false, func(l marketapi.CsvLine) string { return l.CLook }}
+	colCollection   = Column{"collection", false, func(l marketapi.CsvLine) string { return l.CCollection }}
+	colMarketName   = Column{"market_name", false, func(l marketapi.CsvLine) string { return l.CMarketName }}
+	colNameColor    = Column{"name_color", false, func(l marketapi.CsvLine) string { return l.CNameColor }}
+	colPriceUpdated = Column{"price_updated", true, func(l marketapi.CsvLine) string { return l.CPriceUpdated }}
+	colPop          = Column{"pop", true, func(l marketapi.CsvLine) string { return l.CPop }}
+)
+
+//Columns - колонки ItemDB для игры action (marketapi.ActDOTA2, ActCSGO, ActTF2, ActGIFTS).
+func Columns(action string) []Column {
+	common := []Column{colClassID, colInstanceID, colPrice, colOffers, colPopularity, colRarity, colQuality, colHeroID}
+	tail := []Column{colMarketName, colNameColor, colPriceUpdated, colPop}
+	switch action {
+	case marketapi.ActDOTA2:
+		return append(common, tail...)
+	case marketapi.ActCSGO:
+		return append(append(common, colSlot, colStickers), tail...)
+	case marketapi.ActTF2:
+		return append(append(common, colCraftable, colLook, colCollection), tail...)
+	case marketapi.ActGIFTS:
+		return append(append(common, colSlot, colOs, colFeatures, colRating), tail...)
+	default:
+		panic(fmt.Sprintf("Action %s is not defined", action))
+	}
+}
+
+//Snapshot - ItemDB на момент Time.
+type Snapshot struct {
+	Action string
+	Time   time.Time
+	Lines  []marketapi.CsvLine
+}
+
+//Load - текущая ItemDB и время ее создания из ItemDBCurrent.
+func Load(api *marketapi.API) (Snapshot, error) {
+	current, err := api.ItemDBCurrent()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	lines, err := api.ItemDB(current.DB)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Action: api.Action, Time: time.Unix(current.Time, 0), Lines: lines}, nil
+}
+
+func intValue(s string) (int64, bool) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	return v, err == nil
+}
+
+//WriteCSV - CSV через запятую с заголовком. Первая колонка - snapshot (RFC 3339), затем Columns.
+func WriteCSV(w io.Writer, s Snapshot) error {
+	columns := Columns(s.Action)
+	cw := csv.NewWriter(w)
+	header := []string{"snapshot"}
+	for _, c := range columns {
+		header = append(header, c.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	snapshot := s.Time.UTC().Format(time.RFC3339)
+	for _, line := range s.Lines {
+		record := []string{snapshot}
+		for _, c := range columns {
+			value := c.Value(line)
+			if c.Int {
+				if v, ok := intValue(value); ok {
+					value = strconv.FormatInt(v, 10)
+				} else {
+					value = ""
+				}
+			}
+			record = append(record, value)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+//row - строка для JSON Lines и Parquet. Числовые колонки - int64 или nil.
+func row(columns []Column, s Snapshot, line marketapi.CsvLine) map[string]interface{} {
+	r := map[string]interface{}{"snapshot": s.Time.UTC().UnixNano() / int64(time.Millisecond)}
+	for _, c := range columns {
+		value := c.Value(line)
+		if !c.Int {
+			r[c.Name] = value
+		} else if v, ok := intValue(value); ok {
+			r[c.Name] = v
+		} else {
+			r[c.Name] = nil
+		}
+	}
+	return r
+}
+
+//Rows - строки снимка в виде колонка -> значение, как в WriteJSONL.
+func Rows(s Snapshot) []map[string]interface{} {
+	columns := Columns(s.Action)
+	rows := make([]map[string]interface{}, len(s.Lines))
+	for i, line := range s.Lines {
+		rows[i] = row(columns, s, line)
+	}
+	return rows
+}
+
+//WriteJSONL - по одному JSON объекту на строку. snapshot - unix time в миллисекундах.
+func WriteJSONL(w io.Writer, s Snapshot) error {
+	enc := json.NewEncoder(w)
+	for _, r := range Rows(s) {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+//go:build parquet
+
+//Package parquet - выгрузка export.Snapshot в Parquet через github.com/xitongsys/parquet-go.
+//Собирается с тегом parquet, чтобы export и marketctl не зависели от parquet-go:
+//
+//	go build -tags parquet
+//
+//Проверено с github.com/xitongsys/parquet-go v1.6.2.
+package parquet
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/soluchok/marketapi/export"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	pq "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+//Schema - JSON схема parquet-go для игры action: snapshot - TIMESTAMP_MILLIS,
+//числовые колонки - необязательные INT64, остальные - UTF8.
+func Schema(action string) string {
+	fields := []string{`{"Tag":"name=snapshot, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=REQUIRED"}`}
+	for _, c := range export.Columns(action) {
+		if c.Int {
+			fields = append(fields, `{"Tag":"name=`+c.Name+`, type=INT64, repetitiontype=OPTIONAL"}`)
+		} else {
+			fields = append(fields, `{"Tag":"name=`+c.Name+`, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED"}`)
+		}
+	}
+	return `{"Tag":"name=itemdb, repetitiontype=REQUIRED","Fields":[` + strings.Join(fields, ",") + `]}`
+}
+
+//Write - Parquet файл со схемой Schema, сжатие Snappy.
+func Write(w io.Writer, s export.Snapshot) error {
+	pw, err := writer.NewJSONWriter(Schema(s.Action), writerfile.NewWriterFile(w), 1)
+	if err != nil {
+		return err
+	}
+	pw.CompressionType = pq.CompressionCodec_SNAPPY
+
+	for _, row := range export.Rows(s) {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if err := pw.Write(string(data)); err != nil {
+			return err
+		}
+	}
+	return pw.WriteStop()
+}
@@ -0,0 +1,139 @@
+package marketapi
+
+import (
+	"math"
+	"strconv"
+)
+
+//FairValueSignals - исходные данные для оценки, все цены в копейках. 0 - сигнала нет.
+type FairValueSignals struct {
+	DBPrice         int64   // CPrice из ItemDB
+	LowestOffer     int64   // самое дешевое предложение из ItemInfo.Offers
+	HighestBuyOffer int64   // самая дорогая заявка из ItemInfo.BuyOffers
+	Median          float64 // медиана продаж из ItemHistory
+	Volatility      float64
+	Sales           int
+}
+
+//FairValueEstimate - оценка справедливой цены.
+//Confidence - от 0 до 1, Spread - разница между лучшим предложением и лучшей заявкой.
+type FairValueEstimate struct {
+	Value      int64
+	Confidence float64
+	Spread     int64
+	Signals    FairValueSignals
+}
+
+//FairValueWeighting - стратегия объединения сигналов в одну оценку.
+type FairValueWeighting interface {
+	Estimate(signals FairValueSignals) FairValueEstimate
+}
+
+//WeightedAverage - взвешенное среднее доступных сигналов.
+//Confidence - доля веса доступных сигналов, уменьшенная при высокой волатильности и малом числе продаж.
+type WeightedAverage struct {
+	DB       float64
+	Offer    float64
+	BuyOffer float64
+	History  float64
+}
+
+//DefaultWeighting - больше всего веса у истории продаж, затем у текущих предложений.
+var DefaultWeighting = WeightedAverage{DB: 1, Offer: 2, BuyOffer: 1, History: 3}
+
+func (w WeightedAverage) Estimate(s FairValueSignals) FairValueEstimate {
+	var sum, weight float64
+	add := func(value float64, w float64) {
+		if value > 0 && w > 0 {
+			sum += value * w
+			weight += w
+		}
+	}
+	add(float64(s.DBPrice), w.DB)
+	add(float64(s.LowestOffer), w.Offer)
+	add(float64(s.HighestBuyOffer), w.BuyOffer)
+	add(s.Median, w.History)
+
+	estimate := FairValueEstimate{Signals: s}
+	if weight == 0 {
+		return estimate
+	}
+	estimate.Value = int64(sum/weight + 0.5)
+	if s.LowestOffer > 0 && s.HighestBuyOffer > 0 {
+		estimate.Spread = s.LowestOffer - s.HighestBuyOffer
+	}
+	estimate.Confidence = weight / (w.DB + w.Offer + w.BuyOffer + w.History)
+	estimate.Confidence /= 1 + s.Volatility
+	estimate.Confidence *= math.Min(1, float64(s.Sales)/50)
+	return estimate
+}
+
+//ItemDBIndex - текущая ItemDB с ключом classid_instanceid.
+func (a *API) ItemDBIndex() (map[string]CsvLine, error) {
+	lines, err := a.CurrentItemDB()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]CsvLine, len(lines))
+	for _, line := range lines {
+		index[orderKey(line.CClassID, line.CInstanceID)] = line
+	}
+	return index, nil
+}
+
+//FairValuer - общая модель оценки для всех ботов.
+//DB - результат ItemDBIndex, если nil, цена из ItemDB не используется.
+//Weighting - если nil, используется DefaultWeighting.
+type FairValuer struct {
+	API       *API
+	DB        map[string]CsvLine
+	Weighting FairValueWeighting
+}
+
+//Signals - собирает сигналы из ItemDB, ItemInfo и ItemHistory.
+func (f *FairValuer) Signals(classid string, instanceid string) (FairValueSignals, error) {
+	var signals FairValueSignals
+	if line, ok := f.DB[orderKey(classid, instanceid)]; ok {
+		signals.DBPrice, _ = strconv.ParseInt(line.CPrice, 10, 64)
+	}
+
+	info, err := f.API.ItemInfo(classid, instanceid)
+	if err != nil {
+		return signals, err
+	}
+	for _, offer := range info.Offers {
+		price, err := strconv.ParseInt(offer.Price, 10, 64)
+		if err == nil && (signals.LowestOffer == 0 || price < signals.LowestOffer) {
+			signals.LowestOffer = price
+		}
+	}
+	for _, offer := range info.BuyOffers {
+		price, err := strconv.ParseInt(offer.OPrice, 10, 64)
+		if err == nil && price > signals.HighestBuyOffer {
+			signals.HighestBuyOffer = price
+		}
+	}
+
+	history, err := f.API.ItemHistory(classid, instanceid)
+	if err != nil {
+		return signals, err
+	}
+	series := history.Series()
+	signals.Median = Median(series)
+	signals.Volatility = Volatility(series)
+	signals.Sales = len(series)
+	return signals, nil
+}
+
+//FairValue - оценка справедливой цены предмета.
+func (f *FairValuer) FairValue(classid string, instanceid string) (FairValueEstimate, error) {
+	signals, err := f.Signals(classid, instanceid)
+	if err != nil {
+		return FairValueEstimate{}, err
+	}
+	weighting := f.Weighting
+	if weighting == nil {
+		weighting = DefaultWeighting
+	}
+	return weighting.Estimate(signals), nil
+}
@@ -0,0 +1,108 @@
+package marketapi
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+//InventoryState - состояние обновления инвентаря из InventoryStatus.IStatus.
+type InventoryState int
+
+const (
+	InventoryUnknown  InventoryState = iota // IStatus не распознан
+	InventoryIdle                           // IStatus = 0, обновление не запускалось
+	InventoryUpdating                       // IStatus = 1, обновление идет
+	InventoryUpdated                        // IStatus = 2, инвентарь обновлен
+	InventoryFailed                         // IStatus = 3, ошибка обновления
+)
+
+func (s InventoryState) String() string {
+	switch s {
+	case InventoryIdle:
+		return "idle"
+	case InventoryUpdating:
+		return "updating"
+	case InventoryUpdated:
+		return "updated"
+	case InventoryFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+//State - разобранный IStatus.
+func (s APIInventoryStatus) State() InventoryState {
+	switch s.IStatus {
+	case "0":
+		return InventoryIdle
+	case "1":
+		return InventoryUpdating
+	case "2":
+		return InventoryUpdated
+	case "3":
+		return InventoryFailed
+	}
+	return InventoryUnknown
+}
+
+//UpdateInventoryAndWait - Запускает UpdateInventory и опрашивает InventoryStatus (с паузой от 1 до 30 секунд,
+//удваивая ее после каждого опроса), пока IStatus не станет InventoryUpdated (готово) или InventoryFailed
+//(ошибка ErrAPIInventory). Если IStatus не распознан, готовым считается изменение времени обновления ITime.
+//Возвращает итоговый статус и время ожидания.
+func (a *API) UpdateInventoryAndWait(ctx context.Context) (APIInventoryRefresh, error) {
+	api := a.WithContext(ctx)
+	before, err := api.InventoryStatus()
+	if err != nil {
+		return APIInventoryRefresh{}, err
+	}
+	refresh := APIInventoryRefresh{Started: time.Now()}
+	update, err := api.UpdateInventory()
+	if err != nil {
+		return refresh, err
+	}
+	if !update.Success {
+		return refresh, errors.New(ErrAPIInventory)
+	}
+
+	delay := time.Second
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			refresh.Duration = time.Since(refresh.Started)
+			return refresh, ctx.Err()
+		case <-timer.C:
+		}
+
+		status, err := api.InventoryStatus()
+		refresh.Polls++
+		refresh.Duration = time.Since(refresh.Started)
+		if err != nil {
+			return refresh, err
+		}
+		refresh.Status = status
+		refresh.State = status.State()
+		if !status.Success {
+			return refresh, errors.New(ErrAPIInventory)
+		}
+		switch refresh.State {
+		case InventoryFailed:
+			return refresh, errors.New(ErrAPIInventory)
+		case InventoryUpdated:
+			if status.ITime != before.ITime || before.State() != InventoryUpdated {
+				return refresh, nil
+			}
+		case InventoryUnknown:
+			if status.ITime != before.ITime {
+				return refresh, nil
+			}
+		}
+
+		delay *= 2
+		if delay > 30*time.Second {
+			delay = 30 * time.Second
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package marketapi
+
+import (
+	"context"
+	"time"
+)
+
+//HealthEvent - результат одной проверки Keepalive.
+type HealthEvent struct {
+	Time     time.Time
+	Online   bool   // PingPong прошел и сайт считает аккаунт онлайн
+	TempBan  bool   // Status.SiteNotmpban = false
+	Failures int    // подряд неудачных PingPong
+	Status   Status // флаги из Test()
+	Err      error
+}
+
+//Keepalive - держит аккаунт онлайн, вызывая PingPong каждые Interval (по умолчанию 3 минуты),
+//и проверяет флаги Test(). Аккаунт считается офлайн после MaxFailures (по умолчанию 1)
+//неудачных PingPong подряд или если Test() сообщает, что продажи невозможны.
+type Keepalive struct {
+	API         *API
+	Interval    time.Duration
+	MaxFailures int
+	OnEvent     func(event HealthEvent) // после каждой проверки
+	OnOffline   func(event HealthEvent) // аккаунт перешел в офлайн
+	OnTempBan   func(event HealthEvent) // аккаунт получил временный бан
+
+	failures int
+	last     HealthEvent
+}
+
+//Check - одна проверка: PingPong и Test().
+func (k *Keepalive) Check() HealthEvent {
+	event := HealthEvent{Time: time.Now()}
+	if _, err := k.API.PingPong(); err != nil {
+		k.failures++
+		event.Err = err
+	} else {
+		k.failures = 0
+	}
+	event.Failures = k.failures
+
+	test, err := k.API.Test()
+	if err != nil && event.Err == nil {
+		event.Err = err
+	}
+	event.Status = test.Status
+
+	maxFailures := k.MaxFailures
+	if maxFailures == 0 {
+		maxFailures = 1
+	}
+	event.Online = k.failures < maxFailures && err == nil &&
+		test.Status.UserToken && test.Status.TradeCheck && test.Status.SiteOnline && test.Status.SiteNotmpban
+	event.TempBan = err == nil && !test.Status.SiteNotmpban
+
+	if k.OnEvent != nil {
+		k.OnEvent(event)
+	}
+	if !event.Online && (k.last.Online || k.last.Time.IsZero()) && k.OnOffline != nil {
+		k.OnOffline(event)
+	}
+	if event.TempBan && !k.last.TempBan && k.OnTempBan != nil {
+		k.OnTempBan(event)
+	}
+	k.last = event
+	return event
+}
+
+//Run - вызывает Check каждые Interval до отмены ctx.
+func (k *Keepalive) Run(ctx context.Context) error {
+	return runEvery(ctx, k.Interval, 3*time.Minute, func() {
+		k.Check()
+	})
+}
@@ -0,0 +1,69 @@
+package marketapi
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+//Logger - логгер запросов. Ему удовлетворяет *slog.Logger.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...interface{})
+}
+
+//requestInfo - сведения об одном запросе.
+type requestInfo struct {
+	Endpoint string
+	Wait     time.Duration // ожидание в лимитере, сумма по всем попыткам
+	Latency  time.Duration // время запроса вместе с повторами, без ожидания в лимитере
+	Retries  int           // число повторов
+	Status   int           // статус последней попытки
+	Body     []byte
+}
+
+//endpointName - имя метода по адресу запроса: ItemInfo, v2/items, itemdb.
+func endpointName(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	if i := strings.IndexByte(url, '?'); i >= 0 {
+		url = url[:i]
+	}
+	parts := strings.Split(url, "/")
+	switch {
+	case len(parts) > 3 && parts[1] == "api" && parts[2] == "v2":
+		return "v2/" + parts[3]
+	case len(parts) > 2 && parts[1] == "api":
+		return parts[2]
+	case len(parts) > 1:
+		return parts[1]
+	}
+	return ""
+}
+
+//logRequest - запись о запросе в a.Logger: Info для успешных, Warn для ошибок,
+//тело ответа пишется на уровне Debug, если включен a.LogBodies.
+func (a *API) logRequest(ctx context.Context, info requestInfo, err error) {
+	if a.Logger == nil {
+		return
+	}
+	args := []interface{}{
+		"endpoint", info.Endpoint,
+		"game", a.Action,
+		"key", a.Key.String(),
+		"latency", info.Latency,
+		"wait", info.Wait,
+		"retries", info.Retries,
+		"status", info.Status,
+	}
+	if err != nil {
+		a.Logger.WarnContext(ctx, "marketapi request failed", append(args, "error", err.Error())...)
+	} else {
+		a.Logger.InfoContext(ctx, "marketapi request", args...)
+	}
+	if a.LogBodies && info.Body != nil {
+		a.Logger.DebugContext(ctx, "marketapi response", "endpoint", info.Endpoint, "body", a.Redact(string(info.Body)))
+	}
+}
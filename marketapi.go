@@ -2,6 +2,7 @@ package marketapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
@@ -11,7 +12,7 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
-	"sync"
+	"time"
 )
 
 func (i *Item) reload() {
@@ -19,6 +20,16 @@ func (i *Item) reload() {
 	json.Unmarshal([]byte(i.ITagsString), &i.ITags)
 }
 
+//UnmarshalJSON - декодирует Item и разбирает описания и теги, которые маркет присылает строками JSON.
+func (i *Item) UnmarshalJSON(data []byte) error {
+	type item Item
+	if err := json.Unmarshal(data, (*item)(i)); err != nil {
+		return err
+	}
+	i.reload()
+	return nil
+}
+
 func (r *APIResponse) Success() bool {
 	if r.RespError != nil {
 		return false
@@ -43,30 +54,149 @@ func (r *APIResponse) Error() string {
 	return err + result
 }
 
-var mutex = &sync.Mutex{}
+//WithContext - копия API, все запросы которой выполняются с ctx (отмена, трассировка).
+func (a *API) WithContext(ctx context.Context) *API {
+	b := *a
+	b.ctx = ctx
+	return &b
+}
+
+func (a *API) context() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+	return a.ctx
+}
+
+func (a *API) makeGet(url string, attrs ...interface{}) ([]byte, error) {
+	return a.makeGetContext(a.context(), url, attrs...)
+}
+
+//makeGetContext - GET запрос с учетом лимита запросов на ключ a.Key и записью в a.Logger и a.Metrics.
+//attrs - дополнительные атрибуты спана запроса (classid, instanceid, price и т.п.), пары ключ, значение.
+func (a *API) makeGetContext(ctx context.Context, url string, attrs ...interface{}) ([]byte, error) {
+	info := requestInfo{Endpoint: endpointName(url)}
+	ctx, span := a.startSpan(ctx, "marketapi."+info.Endpoint, append([]interface{}{
+		"endpoint", info.Endpoint,
+		"game", a.Action,
+		"path", a.Redact(requestPath(url)),
+	}, attrs...)...)
+	start := time.Now()
+	bytes, err := a.doGet(ctx, url, &info)
+	info.Latency = time.Since(start) - info.Wait
+	a.logRequest(ctx, info, err)
+	if a.Metrics != nil {
+		a.Metrics.observe(a, info, err)
+	}
+	span.SetAttribute("http.status_code", info.Status)
+	span.SetAttribute("retries", info.Retries)
+	span.SetAttribute("result", requestResult(err, info))
+	endSpan(span, err)
+	return bytes, err
+}
+
+//DefaultRetryDelay - пауза перед первым повтором запроса, если API.RetryDelay не задан.
+const DefaultRetryDelay = 500 * time.Millisecond
+
+//mutatingEndpoints - методы, меняющие состояние аккаунта. Они не повторяются, чтобы не купить
+//или не перевести деньги дважды, если первый запрос на самом деле выполнился.
+var mutatingEndpoints = map[string]bool{
+	"Buy":                       true,
+	"SetPrice":                  true,
+	"RemoveAll":                 true,
+	"ItemRequest":               true,
+	"UpdateInventory":           true,
+	"SetToken":                  true,
+	"QuickBuy":                  true,
+	"InsertOrder":               true,
+	"UpdateOrder":               true,
+	"DeleteOrders":              true,
+	"UpdateNotification":        true,
+	"v2/add-to-sale":            true,
+	"v2/set-price":              true,
+	"v2/buy":                    true,
+	"v2/trade-request-give-p2p": true,
+	"v2/money-send":             true,
+}
+
+//retryable - можно ли повторить запрос: таймаут, ошибка сервера 5xx или сети, но не отмена ctx.
+func retryable(ctx context.Context, err error, info *requestInfo) bool {
+	if ctx.Err() != nil || mutatingEndpoints[info.Endpoint] {
+		return false
+	}
+	return err.Error() == ErrAPITimeout || info.Status >= 500 || info.Status == 0
+}
 
-func makeGet(url string) ([]byte, error) {
-	mutex.Lock()
-	defer func() {
-		mutex.Unlock()
-	}()
+//doGet - запрос с повторами: до a.Retries раз, если retryable. Число повторов пишется в info.Retries,
+//каждая попытка - дочерний спан marketapi.attempt.
+func (a *API) doGet(ctx context.Context, url string, info *requestInfo) ([]byte, error) {
+	delay := a.RetryDelay
+	if delay == 0 {
+		delay = DefaultRetryDelay
+	}
+	for {
+		attemptCtx, attempt := a.startSpan(ctx, "marketapi.attempt", "attempt", info.Retries+1)
+		bytes, err := a.getOnce(attemptCtx, url, info)
+		attempt.SetAttribute("http.status_code", info.Status)
+		endSpan(attempt, err)
+		if err == nil || info.Retries >= a.Retries || !retryable(ctx, err, info) {
+			return bytes, err
+		}
+		info.Retries++
+		if err := sleep(ctx, delay); err != nil {
+			return []byte{}, err
+		}
+		delay *= 2
+	}
+}
 
-	resp, err := http.Get(url)
+//getOnce - одна попытка запроса с ожиданием в лимитере.
+func (a *API) getOnce(ctx context.Context, url string, info *requestInfo) ([]byte, error) {
+	interval := a.RateInterval
+	if interval == 0 {
+		interval = DefaultRateInterval
+	}
+	_, waitSpan := a.startSpan(ctx, "marketapi.ratelimit")
+	wait, err := limiterFor(a.Key.Reveal()).wait(ctx, interval)
+	info.Wait += wait
+	endSpan(waitSpan, err)
 	if err != nil {
 		return []byte{}, err
 	}
+
+	info.Status = 0
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return []byte{}, a.redactError(err)
+	}
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return []byte{}, ctx.Err()
+		}
+		return []byte{}, a.redactError(err)
+	}
 	defer resp.Body.Close()
+	info.Status = resp.StatusCode
 	if resp.StatusCode == http.StatusGatewayTimeout {
 		return []byte{}, errors.New(ErrAPITimeout)
 	}
+	if resp.StatusCode >= 500 {
+		return []byte{}, errors.New(ErrAPIServer)
+	}
 	bytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return []byte{}, err
 	}
+	info.Body = bytes
 	var apiResponse APIResponse
 	json.Unmarshal(bytes, &apiResponse)
 	if !apiResponse.Success() {
-		return []byte{}, errors.New(apiResponse.Error())
+		return []byte{}, errors.New(a.Redact(apiResponse.Error()))
 	}
 	return bytes, nil
 }
@@ -148,7 +278,7 @@ func lineToStruct(action string, line []string) CsvLine {
 }
 
 func (a *API) ItemDBCurrent() (APIItemDBCurrent, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLItemDBCurrent, a.URL, a.Code))
+	bytes, err := a.cachedGet(a.context(), "ItemDBCurrent", fmt.Sprintf(URLItemDBCurrent, a.URL, a.Code))
 	if err != nil {
 		return APIItemDBCurrent{}, err
 	}
@@ -159,7 +289,7 @@ func (a *API) ItemDBCurrent() (APIItemDBCurrent, error) {
 
 func (a *API) ItemDB(dbname string) ([]CsvLine, error) {
 
-	body, err := makeGet(fmt.Sprintf(URLItemDB, a.URL, dbname))
+	body, err := a.makeGet(fmt.Sprintf(URLItemDB, a.URL, dbname))
 	if err != nil {
 		return []CsvLine{}, err
 	}
@@ -184,9 +314,23 @@ func (a *API) ItemDB(dbname string) ([]CsvLine, error) {
 	return data, nil
 }
 
+//CurrentItemDB - ItemDB по имени базы из ItemDBCurrent.
+func (a *API) CurrentItemDB() ([]CsvLine, error) {
+	current, err := a.ItemDBCurrent()
+	if err != nil {
+		return []CsvLine{}, err
+	}
+	return a.ItemDB(current.DB)
+}
+
 //ItemInfo - Информация и предложения о продаже конкретной вещи.
 func (a *API) ItemInfo(classid string, instanceid string) (APIItemInfo, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLItemInfo, a.URL, classid, instanceid, a.Lang, a.Key))
+	return a.itemInfo(a.context(), classid, instanceid)
+}
+
+func (a *API) itemInfo(ctx context.Context, classid string, instanceid string) (APIItemInfo, error) {
+	bytes, err := a.cachedGet(ctx, "ItemInfo", fmt.Sprintf(URLItemInfo, a.URL, classid, instanceid, a.Lang, a.Key.Reveal()),
+		"classid", classid, "instanceid", instanceid)
 	if err != nil {
 		return APIItemInfo{}, err
 	}
@@ -197,7 +341,8 @@ func (a *API) ItemInfo(classid string, instanceid string) (APIItemInfo, error) {
 
 //ItemHistory - Информация о ценах и о последних 500 покупках конкретной вещи.
 func (a *API) ItemHistory(classid string, instanceid string) (APIItemHistory, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLItemHistory, a.URL, classid, instanceid, a.Key))
+	bytes, err := a.cachedGet(a.context(), "ItemHistory", fmt.Sprintf(URLItemHistory, a.URL, classid, instanceid, a.Key.Reveal()),
+		"classid", classid, "instanceid", instanceid)
 	if err != nil {
 		return APIItemHistory{}, err
 	}
@@ -208,7 +353,7 @@ func (a *API) ItemHistory(classid string, instanceid string) (APIItemHistory, er
 
 //MarketTrades - Список трейдов, которые маркет отправил вам и они активны в данный момент.
 func (a *API) MarketTrades() (APIMarketTrades, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLMarketTrades, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLMarketTrades, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIMarketTrades{}, err
 	}
@@ -223,12 +368,15 @@ func (a *API) MarketTrades() (APIMarketTrades, error) {
 // "UIStatus" = 3 - Ожидание передачи боту купленной вами вещи от продавца.
 // "UIStatus" = 4 - Вы можете забрать купленную вещь.
 func (a *API) Trades() (APITrades, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLTrades, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLTrades, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APITrades{}, err
 	}
 	var apiTrades APITrades
 	json.Unmarshal(bytes, &apiTrades)
+	if a.Cache != nil {
+		a.Cache.rememberItems(apiTrades)
+	}
 	return apiTrades, nil
 }
 
@@ -238,7 +386,8 @@ func (a *API) Trades() (APITrades, error) {
 //price - цена в копейках(целое число), уже какого-то выставленного лота, или можно указать любую сумму больше цены самого дешевого лота, во втором случае купится предмет по самой низкой цене.
 //hash - md5 от описания предмета. Вы можете найти его в ответе метода ItemInfo. Это введено, чтобы вы были уверены в покупке именно той вещи, которую покупаете. Если для вас это не интересно, просто пришлите пустую строку.
 func (a *API) Buy(classid string, instanceid string, price int64, hash string) (APIBuy, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLBuy, a.URL, classid, instanceid, price, hash, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLBuy, a.URL, classid, instanceid, price, hash, a.Key.Reveal()),
+		"classid", classid, "instanceid", instanceid, "price", price)
 	if err != nil {
 		return APIBuy{}, err
 	}
@@ -247,21 +396,24 @@ func (a *API) Buy(classid string, instanceid string, price int64, hash string) (
 	if apiBuy.ID == "" {
 		return APIBuy{}, errors.New(apiBuy.Result)
 	}
+	a.InvalidateItem(classid, instanceid)
 	return apiBuy, nil
 }
 
 func (a *API) SetPriceNew(classid string, instanceid string, price int64) (APISetPrice, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLSetPriceNew, a.URL, classid, instanceid, price, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLSetPriceNew, a.URL, classid, instanceid, price, a.Key.Reveal()),
+		"classid", classid, "instanceid", instanceid, "price", price)
 	if err != nil {
 		return APISetPrice{}, err
 	}
 	var apiSetPrice APISetPrice
 	json.Unmarshal(bytes, &apiSetPrice)
+	a.InvalidateItem(classid, instanceid)
 	return apiSetPrice, nil
 }
 
 func (a *API) RemoveAll() (APIRemoveAll, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLRemoveAll, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLRemoveAll, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIRemoveAll{}, err
 	}
@@ -271,17 +423,19 @@ func (a *API) RemoveAll() (APIRemoveAll, error) {
 }
 
 func (a *API) SetPrice(itemid string, price int64) (APISetPrice, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLSetPrice, a.URL, itemid, price, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLSetPrice, a.URL, itemid, price, a.Key.Reveal()),
+		"item_id", itemid, "price", price)
 	if err != nil {
 		return APISetPrice{}, err
 	}
 	var apiSetPrice APISetPrice
 	json.Unmarshal(bytes, &apiSetPrice)
+	a.invalidateItemID(itemid)
 	return apiSetPrice, nil
 }
 
 func (a *API) PingPong() (APIPingPong, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLPingPong, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLPingPong, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIPingPong{}, err
 	}
@@ -290,19 +444,30 @@ func (a *API) PingPong() (APIPingPong, error) {
 	return apiPingPong, nil
 }
 
-func (a *API) ItemRequest(act string, botid string) (APIItemRequest, error) {
-	// act in or out
-	bytes, err := makeGet(fmt.Sprintf(URLItemRequest, a.URL, act, botid, a.Key))
+//ItemRequest - Запрос на передачу предметов боту (ItemRequestIn) или получение купленных (ItemRequestOut).
+//В ответе номер трейда TradeOfferID и секрет Secret, по которым трейд можно подтвердить в Steam.
+func (a *API) ItemRequest(dir ItemRequestDir, botid string) (APIItemRequest, error) {
+	if dir != ItemRequestIn && dir != ItemRequestOut {
+		return APIItemRequest{}, errors.New(ErrAPIBadDir)
+	}
+	if id, err := strconv.ParseUint(botid, 10, 64); err != nil || id == 0 {
+		return APIItemRequest{}, errors.New(ErrAPIBadBotID)
+	}
+	bytes, err := a.makeGet(fmt.Sprintf(URLItemRequest, a.URL, dir, botid, a.Key.Reveal()))
 	if err != nil {
 		return APIItemRequest{}, err
 	}
 	var apiItemRequest APIItemRequest
 	json.Unmarshal(bytes, &apiItemRequest)
+	apiItemRequest.TradeOfferID, err = strconv.ParseUint(apiItemRequest.Trade, 10, 64)
+	if err != nil {
+		return apiItemRequest, errors.New(ErrAPINoTradeID)
+	}
 	return apiItemRequest, nil
 }
 
 func (a *API) OperationHistory(startTime int64, endTime int64) (APIOperationHistory, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLOperationHistory, a.URL, startTime, endTime, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLOperationHistory, a.URL, startTime, endTime, a.Key.Reveal()))
 	if err != nil {
 		return APIOperationHistory{}, err
 	}
@@ -312,7 +477,7 @@ func (a *API) OperationHistory(startTime int64, endTime int64) (APIOperationHist
 }
 
 func (a *API) GetMoney() (APIGetMoney, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLGetMoney, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLGetMoney, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIGetMoney{}, err
 	}
@@ -322,7 +487,7 @@ func (a *API) GetMoney() (APIGetMoney, error) {
 }
 
 func (a *API) Test() (APITest, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLTest, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLTest, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APITest{}, err
 	}
@@ -332,7 +497,7 @@ func (a *API) Test() (APITest, error) {
 }
 
 func (a *API) InventoryStatus() (APIInventoryStatus, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLInventoryStatus, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLInventoryStatus, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIInventoryStatus{}, err
 	}
@@ -342,7 +507,7 @@ func (a *API) InventoryStatus() (APIInventoryStatus, error) {
 }
 
 func (a *API) UpdateInventory() (APIUpdateInventory, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLUpdateInventory, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLUpdateInventory, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIUpdateInventory{}, err
 	}
@@ -353,7 +518,7 @@ func (a *API) UpdateInventory() (APIUpdateInventory, error) {
 
 //GetToken - Получить установленный токен.
 func (a *API) GetToken() (APIGetToken, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLGetToken, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLGetToken, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIGetToken{}, err
 	}
@@ -362,8 +527,12 @@ func (a *API) GetToken() (APIGetToken, error) {
 	return apiGetToken, nil
 }
 
+//SetToken - Установить токен из ссылки на обмен. Токен проверяется через ValidToken до запроса.
 func (a *API) SetToken(newToken string) (APISetToken, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLSetToken, a.URL, newToken, a.Key))
+	if !ValidToken(newToken) {
+		return APISetToken{}, errors.New(ErrAPIBadToken)
+	}
+	bytes, err := a.makeGet(fmt.Sprintf(URLSetToken, a.URL, newToken, a.Key.Reveal()))
 	if err != nil {
 		return APISetToken{}, err
 	}
@@ -374,21 +543,19 @@ func (a *API) SetToken(newToken string) (APISetToken, error) {
 
 //QuickItems - Получить список предметов для моментальной покупки с страницы BASE_URL/quick/
 func (a *API) QuickItems() (APIQuickItems, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLQuickItems, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLQuickItems, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIQuickItems{}, err
 	}
 	var apiQuickItems APIQuickItems
 	json.Unmarshal([]byte(string(bytes)), &apiQuickItems)
-	for i := range apiQuickItems.Items {
-		apiQuickItems.Items[i].reload()
-	}
 	return apiQuickItems, nil
 }
 
 //QuickBuy - Моментально купить предмет из метода QuickItems (За цену, которая указана в параметре "LPaid" в копейках). Через секунду его можно будет забрать через метод ItemRequest.
 func (a *API) QuickBuy(uiID string) (APIQuickBuy, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLQuickBuy, a.URL, uiID, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLQuickBuy, a.URL, uiID, a.Key.Reveal()),
+		"item_id", uiID)
 	if err != nil {
 		return APIQuickBuy{}, err
 	}
@@ -399,7 +566,7 @@ func (a *API) QuickBuy(uiID string) (APIQuickBuy, error) {
 
 //GetOrders - Получить список выставленных ордеров с страницы BASE_URL/orders/
 func (a *API) GetOrders() (APIGetOrders, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLGetOrders, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLGetOrders, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIGetOrders{}, err
 	}
@@ -413,7 +580,8 @@ func (a *API) GetOrders() (APIGetOrders, error) {
 //price - цена в копейках(целое число), именно с этой ценой вы создате заявку на покупку
 //hash - md5 от описания предмета. Вы можете найти его в ответе метода ItemInfo. Это введено, чтобы вы были уверены в покупке именно той вещи, которую покупаете.
 func (a *API) InsertOrder(classid string, instanceid string, price int64, hash string) (APIInsertOrder, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLInsertOrder, a.URL, classid, instanceid, price, hash, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLInsertOrder, a.URL, classid, instanceid, price, hash, a.Key.Reveal()),
+		"classid", classid, "instanceid", instanceid, "price", price)
 	if err != nil {
 		return APIInsertOrder{}, err
 	}
@@ -426,7 +594,8 @@ func (a *API) InsertOrder(classid string, instanceid string, price int64, hash s
 //classid и instanceid - идентификаторы предмета.
 //price - цена в копейках(целое число), цена указанная в заявке на покупку изменится на указанную тут. Если вы пришлете 0, то эта заявка на покупку будет удалена.
 func (a *API) UpdateOrder(classid string, instanceid string, price int64) (APIUpdateOrder, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLUpdateOrder, a.URL, classid, instanceid, price, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLUpdateOrder, a.URL, classid, instanceid, price, a.Key.Reveal()),
+		"classid", classid, "instanceid", instanceid, "price", price)
 	if err != nil {
 		return APIUpdateOrder{}, err
 	}
@@ -437,7 +606,7 @@ func (a *API) UpdateOrder(classid string, instanceid string, price int64) (APIUp
 
 //DeleteOrders - Удаление всех заявок на покупку.
 func (a *API) DeleteOrders() (APIDeleteOrders, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLDeleteOrders, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLDeleteOrders, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIDeleteOrders{}, err
 	}
@@ -448,7 +617,7 @@ func (a *API) DeleteOrders() (APIDeleteOrders, error) {
 
 //GetNotifications - Получить список включенных уведомлений о изменении цены. BASE_URL/mail/
 func (a *API) GetNotifications() (APIGetNotifications, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLGetNotifications, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLGetNotifications, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIGetNotifications{}, err
 	}
@@ -461,7 +630,8 @@ func (a *API) GetNotifications() (APIGetNotifications, error) {
 //classid и instanceid - идентификаторы предмета.
 //price - цена в копейках(целое число), если появится предложение о покупке ниже этой цены, то вы получите уведомление. Если вы пришлете 0, то это уведомление будет удалено.
 func (a *API) UpdateNotification(classid string, instanceid string, price int64) (APIUpdateNotification, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLUpdateNotification, a.URL, classid, instanceid, price, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLUpdateNotification, a.URL, classid, instanceid, price, a.Key.Reveal()),
+		"classid", classid, "instanceid", instanceid, "price", price)
 	if err != nil {
 		return APIUpdateNotification{}, err
 	}
@@ -472,7 +642,7 @@ func (a *API) UpdateNotification(classid string, instanceid string, price int64)
 
 //GetWSAuth - Ключ для подписки на вебсокеты. Получение приватных оповещений.
 func (a *API) GetWSAuth() (APIGetWSAuth, error) {
-	bytes, err := makeGet(fmt.Sprintf(URLGetWSAuth, a.URL, a.Key))
+	bytes, err := a.makeGet(fmt.Sprintf(URLGetWSAuth, a.URL, a.Key.Reveal()))
 	if err != nil {
 		return APIGetWSAuth{}, err
 	}
@@ -481,14 +651,19 @@ func (a *API) GetWSAuth() (APIGetWSAuth, error) {
 	return apiGetWSAuth, nil
 }
 
-func newAPI(key string, action string, url string, code string) (*API, error) {
-	api := &API{
+//newUncheckedAPI - создание нового объекта API без проверки ключа.
+func newUncheckedAPI(key Key, action string, url string, code string) *API {
+	return &API{
 		Key:    key,
 		Action: action,
 		URL:    url,
 		Lang:   "ru",
 		Code:   code,
 	}
+}
+
+func newAPI(key string, action string, url string, code string) (*API, error) {
+	api := newUncheckedAPI(Key(key), action, url, code)
 
 	_, err := api.Test()
 	if err != nil {
@@ -517,3 +692,30 @@ func NewTf2API(key string) (*API, error) {
 func NewGiftsAPI(key string) (*API, error) {
 	return newAPI(key, ActGIFTS, URLGifts, CodeGIFTS)
 }
+
+//NewGameAPI - создание нового объекта API по названию игры: dota2, csgo, tf2 или gifts.
+func NewGameAPI(game string, key string) (*API, error) {
+	g, ok := games[game]
+	if !ok {
+		return nil, errors.New(ErrAPIBadGame)
+	}
+	return newAPI(key, g.action, g.url, g.code)
+}
+
+//GameAction - Action для игры game ("dota2", "csgo", "tf2" или "gifts"), неизвестная игра - ошибка ErrAPIBadGame.
+func GameAction(game string) (string, error) {
+	g, ok := games[game]
+	if !ok {
+		return "", errors.New(ErrAPIBadGame)
+	}
+	return g.action, nil
+}
+
+//NewGameAPIFromSecrets - создание нового объекта API с ключом name из secrets.
+func NewGameAPIFromSecrets(game string, secrets SecretProvider, name string) (*API, error) {
+	key, err := secrets.Key(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewGameAPI(game, key.Reveal())
+}
@@ -0,0 +1,39 @@
+package marketapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"money":1500}`))
+	}))
+	defer server.Close()
+
+	a := &API{Key: "test-retries", URL: server.URL, RateInterval: time.Millisecond, Retries: 2, RetryDelay: time.Millisecond}
+	info := requestInfo{Endpoint: "GetMoney"}
+	if _, err := a.doGet(a.context(), server.URL+"/api/GetMoney/?key=test-retries", &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Retries != 2 || calls != 3 {
+		t.Fatalf("Retries = %d, calls = %d, want 2 and 3", info.Retries, calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	info = requestInfo{Endpoint: "Buy"}
+	if _, err := a.doGet(a.context(), server.URL+"/api/Buy/1_2/100//?key=test-retries", &info); err == nil || err.Error() != ErrAPIServer {
+		t.Fatalf("Buy err = %v, want %s", err, ErrAPIServer)
+	}
+	if info.Retries != 0 || calls != 1 {
+		t.Fatalf("Buy was retried: Retries = %d, calls = %d", info.Retries, calls)
+	}
+}
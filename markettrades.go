@@ -0,0 +1,46 @@
+package marketapi
+
+//MarketTradeMatch - трейд маркета вместе с предметами из Trades и ответом ItemRequest, который его создал.
+type MarketTradeMatch struct {
+	MarketTrade MarketTrade
+	Trades      []Trade
+	Offer       *APIItemRequest // nil, если трейд создан не через переданные offers
+}
+
+//MatchMarketTrades - сопоставляет трейды из MarketTrades с предметами из Trades (по ui_id,
+//а если его нет - по боту и classid/instanceid) и с ответами ItemRequest (по номеру трейда).
+//Каждый предмет из Trades попадает не более чем в одно совпадение.
+func MatchMarketTrades(market APIMarketTrades, trades APITrades, offers []APIItemRequest) []MarketTradeMatch {
+	byID := map[string]Trade{}
+	for _, trade := range trades {
+		byID[trade.UIID] = trade
+	}
+
+	used := map[string]bool{}
+	result := make([]MarketTradeMatch, 0, len(market.Trades))
+	for _, mt := range market.Trades {
+		match := MarketTradeMatch{MarketTrade: mt}
+		for _, item := range mt.Items {
+			if trade, ok := byID[item.UIID]; ok && !used[trade.UIID] {
+				used[trade.UIID] = true
+				match.Trades = append(match.Trades, trade)
+				continue
+			}
+			for _, trade := range trades {
+				if !used[trade.UIID] && trade.UIBid == mt.BotID && trade.IClassID == item.ClassID && trade.IInstanceID == item.InstanceID {
+					used[trade.UIID] = true
+					match.Trades = append(match.Trades, trade)
+					break
+				}
+			}
+		}
+		for i := range offers {
+			if offers[i].Trade == mt.TradeID {
+				match.Offer = &offers[i]
+				break
+			}
+		}
+		result = append(result, match)
+	}
+	return result
+}
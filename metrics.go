@@ -0,0 +1,191 @@
+package marketapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//MetricsBuckets - границы корзин гистограмм в секундах.
+var MetricsBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	counts []uint64 // по MetricsBuckets, без накопления
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(MetricsBuckets))
+	}
+	v := d.Seconds()
+	for i, bound := range MetricsBuckets {
+		if v <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+//Metrics - счетчики и гистограммы запросов и показатели аккаунтов.
+//ServeHTTP отдает их в текстовом формате Prometheus.
+type Metrics struct {
+	mutex    sync.Mutex
+	requests map[string]uint64     // endpoint, game
+	retries  map[string]uint64     // endpoint, game
+	errors   map[string]uint64     // endpoint, game, class, retried
+	latency  map[string]*histogram // endpoint, game
+	wait     map[string]*histogram // endpoint, game
+	gauges   map[string]float64    // полное имя с метками
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: map[string]uint64{},
+		retries:  map[string]uint64{},
+		errors:   map[string]uint64{},
+		latency:  map[string]*histogram{},
+		wait:     map[string]*histogram{},
+		gauges:   map[string]float64{},
+	}
+}
+
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+	}
+	return strings.Join(parts, ",")
+}
+
+//errorClass - класс ошибки для метрик: canceled, timeout, server (5xx), network или api.
+func errorClass(err error, info requestInfo) string {
+	switch {
+	case err == context.Canceled || err == context.DeadlineExceeded:
+		return "canceled"
+	case err.Error() == ErrAPITimeout:
+		return "timeout"
+	case info.Status >= 500:
+		return "server"
+	case info.Status == 0:
+		return "network"
+	}
+	return "api"
+}
+
+func (m *Metrics) observe(a *API, info requestInfo, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	l := labels("endpoint", info.Endpoint, "game", a.Action)
+	m.requests[l]++
+	if info.Retries > 0 {
+		m.retries[l] += uint64(info.Retries)
+	}
+	if err != nil {
+		retried := "false"
+		if info.Retries > 0 {
+			retried = "true"
+		}
+		m.errors[labels("endpoint", info.Endpoint, "game", a.Action, "class", errorClass(err, info), "retried", retried)]++
+	}
+	if m.latency[l] == nil {
+		m.latency[l] = &histogram{}
+		m.wait[l] = &histogram{}
+	}
+	m.latency[l].observe(info.Latency)
+	m.wait[l].observe(info.Wait)
+}
+
+//ObserveAccount - обновляет показатели аккаунта из AccountStatus: баланс, число ордеров и предметов по UIStatus.
+func (m *Metrics) ObserveAccount(a *API, status APIAccountStatus) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	account := a.Key.String()
+	m.gauges["marketapi_balance_kopecks{"+labels("game", a.Action, "account", account)+"}"] = float64(status.Money)
+	m.gauges["marketapi_open_orders{"+labels("game", a.Action, "account", account)+"}"] = float64(status.Orders)
+	prefix := "marketapi_trades{" + labels("game", a.Action, "account", account) + ","
+	for name := range m.gauges {
+		if strings.HasPrefix(name, prefix) {
+			delete(m.gauges, name)
+		}
+	}
+	for uiStatus, count := range status.Trades {
+		m.gauges["marketapi_trades{"+labels("game", a.Action, "account", account, "ui_status", uiStatus)+"}"] = float64(count)
+	}
+}
+
+func sortedKeys(values interface{}) []string {
+	var keys []string
+	switch v := values.(type) {
+	case map[string]uint64:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*histogram:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]float64:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeHistogram(w io.Writer, name string, help string, values map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, l := range sortedKeys(values) {
+		h := values[l]
+		var cumulative uint64
+		for i, bound := range MetricsBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%g\"} %d\n", name, l, bound, cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", name, l, h.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, l, h.count)
+	}
+}
+
+//WriteText - все метрики в текстовом формате Prometheus.
+func (m *Metrics) WriteText(w io.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	fmt.Fprintf(w, "# HELP marketapi_requests_total Requests to the market API.\n# TYPE marketapi_requests_total counter\n")
+	for _, l := range sortedKeys(m.requests) {
+		fmt.Fprintf(w, "marketapi_requests_total{%s} %d\n", l, m.requests[l])
+	}
+	fmt.Fprintf(w, "# HELP marketapi_retries_total Repeated attempts of failed requests.\n# TYPE marketapi_retries_total counter\n")
+	for _, l := range sortedKeys(m.retries) {
+		fmt.Fprintf(w, "marketapi_retries_total{%s} %d\n", l, m.retries[l])
+	}
+	fmt.Fprintf(w, "# HELP marketapi_errors_total Failed requests by error class and whether they were retried.\n# TYPE marketapi_errors_total counter\n")
+	for _, l := range sortedKeys(m.errors) {
+		fmt.Fprintf(w, "marketapi_errors_total{%s} %d\n", l, m.errors[l])
+	}
+	writeHistogram(w, "marketapi_request_duration_seconds", "Request latency without rate limiter wait.", m.latency)
+	writeHistogram(w, "marketapi_ratelimit_wait_seconds", "Time spent waiting for the rate limiter.", m.wait)
+	last := ""
+	for _, name := range sortedKeys(m.gauges) {
+		if metric := name[:strings.IndexByte(name, '{')]; metric != last {
+			fmt.Fprintf(w, "# TYPE %s gauge\n", metric)
+			last = metric
+		}
+		fmt.Fprintf(w, "%s %g\n", name, m.gauges[name])
+	}
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteText(w)
+}
@@ -0,0 +1,196 @@
+package marketapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+//OrderTarget - желаемая заявка на покупку.
+//MaxPrice - потолок цены в копейках, Step - на сколько копеек перебивать лучшую чужую заявку.
+//Quantity - сколько предметов купить (0 - один). Заявка маркета покупает один предмет,
+//поэтому после исполнения она выставляется снова, пока не куплено Quantity предметов.
+type OrderTarget struct {
+	ClassID    string
+	InstanceID string
+	Hash       string
+	MaxPrice   int64
+	Step       int64
+	Quantity   int64
+}
+
+func (t OrderTarget) quantity() int64 {
+	if t.Quantity <= 0 {
+		return 1
+	}
+	return t.Quantity
+}
+
+//OrderAction - действие, выполненное OrderManager.
+type OrderAction struct {
+	ClassID    string
+	InstanceID string
+	OldPrice   int64 // 0 - заявки не было
+	NewPrice   int64 // 0 - заявка удалена
+	Remaining  int64 // сколько предметов осталось купить
+	Err        error
+}
+
+//OrderManager - поддерживает набор заявок на покупку OrderTarget.
+//Заявки, которых нет в Targets, и заявки, которые нельзя перебить не превышая MaxPrice, удаляются.
+//Сумма цена×оставшееся количество по всем заявкам не превышает Budget (если задан) и баланс из GetMoney.
+//Заявка, пропавшая из GetOrders без удаления через OrderManager, считается исполненной.
+type OrderManager struct {
+	API      *API
+	Targets  []OrderTarget
+	Budget   int64
+	Interval time.Duration // по умолчанию 1 минута
+	Report   func(action OrderAction)
+
+	placed map[string]bool  // заявки, выставленные OrderManager
+	bought map[string]int64 // исполненные заявки по orderKey
+}
+
+//Bought - сколько предметов куплено по заявкам OrderManager.
+func (m *OrderManager) Bought(classid string, instanceid string) int64 {
+	return m.bought[orderKey(classid, instanceid)]
+}
+
+func orderKey(classid string, instanceid string) string {
+	return classid + "_" + instanceid
+}
+
+//topBuyOffer - лучшая чужая заявка на покупку.
+func topBuyOffer(info APIItemInfo) int64 {
+	var top int64
+	for _, offer := range info.BuyOffers {
+		count, _ := strconv.ParseInt(offer.C, 10, 64)
+		myCount, _ := strconv.ParseInt(offer.MyCount, 10, 64)
+		if count <= myCount {
+			continue
+		}
+		price, err := strconv.ParseInt(offer.OPrice, 10, 64)
+		if err == nil && price > top {
+			top = price
+		}
+	}
+	return top
+}
+
+//Reconcile - приводит заявки на маркете к Targets.
+func (m *OrderManager) Reconcile() ([]OrderAction, error) {
+	orders, err := m.API.GetOrders()
+	if err != nil {
+		return nil, err
+	}
+	money, err := m.API.GetMoney()
+	if err != nil {
+		return nil, err
+	}
+	budget := money.Money
+	if m.Budget > 0 && m.Budget < budget {
+		budget = m.Budget
+	}
+
+	current := map[string]int64{}
+	for _, order := range orders.Orders {
+		price, _ := strconv.ParseInt(order.OPrice, 10, 64)
+		current[orderKey(order.IClassID, order.IInstanceID)] = price
+	}
+	if m.placed == nil {
+		m.placed = map[string]bool{}
+		m.bought = map[string]int64{}
+	}
+	for key := range m.placed {
+		if _, ok := current[key]; !ok {
+			m.bought[key]++
+			delete(m.placed, key)
+		}
+	}
+
+	var actions []OrderAction
+	wanted := map[string]bool{}
+	var exposure int64
+	for _, target := range m.Targets {
+		key := orderKey(target.ClassID, target.InstanceID)
+		wanted[key] = true
+		old, exists := current[key]
+
+		remaining := target.quantity() - m.bought[key]
+		var price int64
+		if remaining > 0 {
+			info, err := m.API.ItemInfo(target.ClassID, target.InstanceID)
+			if err != nil {
+				return actions, err
+			}
+			price = topBuyOffer(info) + target.Step
+			if price > target.MaxPrice || exposure+price*remaining > budget {
+				price = 0
+			}
+		}
+		if price == old {
+			exposure += price * remaining
+			if price > 0 {
+				m.placed[key] = true
+			}
+			continue
+		}
+		if price == 0 && !exists {
+			continue
+		}
+
+		action := OrderAction{
+			ClassID:    target.ClassID,
+			InstanceID: target.InstanceID,
+			OldPrice:   old,
+			NewPrice:   price,
+			Remaining:  remaining,
+		}
+		if exists {
+			_, action.Err = m.API.UpdateOrder(target.ClassID, target.InstanceID, price)
+		} else {
+			_, action.Err = m.API.InsertOrder(target.ClassID, target.InstanceID, price, target.Hash)
+		}
+		if action.Err == nil {
+			exposure += price * remaining
+			if price > 0 {
+				m.placed[key] = true
+			} else {
+				delete(m.placed, key)
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	for _, order := range orders.Orders {
+		if wanted[orderKey(order.IClassID, order.IInstanceID)] {
+			continue
+		}
+		action := OrderAction{
+			ClassID:    order.IClassID,
+			InstanceID: order.IInstanceID,
+			OldPrice:   current[orderKey(order.IClassID, order.IInstanceID)],
+		}
+		_, action.Err = m.API.UpdateOrder(order.IClassID, order.IInstanceID, 0)
+		if action.Err == nil {
+			delete(m.placed, orderKey(order.IClassID, order.IInstanceID))
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+//Run - запускает Reconcile каждые Interval до отмены ctx.
+func (m *OrderManager) Run(ctx context.Context) error {
+	return runEvery(ctx, m.Interval, time.Minute, func() {
+		actions, err := m.Reconcile()
+		if m.Report != nil {
+			for _, action := range actions {
+				m.Report(action)
+			}
+			if err != nil {
+				m.Report(OrderAction{Err: err})
+			}
+		}
+	})
+}
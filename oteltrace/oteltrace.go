@@ -0,0 +1,74 @@
+//go:build otel
+
+//Package oteltrace - адаптер marketapi.Tracer к OpenTelemetry.
+//Собирается с тегом otel, чтобы основной пакет не зависел от OpenTelemetry:
+//
+//	go build -tags otel
+//
+//Проверено с go.opentelemetry.io/otel v1.43.0.
+package oteltrace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/soluchok/marketapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//Tracer - marketapi.Tracer поверх trace.Tracer, например otel.Tracer("marketapi").
+type Tracer struct {
+	Tracer trace.Tracer
+}
+
+func New(tracer trace.Tracer) Tracer {
+	return Tracer{Tracer: tracer}
+}
+
+func (t Tracer) Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, marketapi.Span) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs)/2)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		kvs = append(kvs, keyValue(fmt.Sprint(attrs[i]), attrs[i+1]))
+	}
+	ctx, span := t.Tracer.Start(ctx, name, trace.WithAttributes(kvs...))
+	return ctx, Span{span}
+}
+
+//Span - marketapi.Span поверх trace.Span. RecordError также выставляет статус спана Error.
+type Span struct {
+	Span trace.Span
+}
+
+func (s Span) SetAttribute(key string, value interface{}) {
+	s.Span.SetAttributes(keyValue(key, value))
+}
+
+func (s Span) RecordError(err error) {
+	s.Span.RecordError(err)
+	s.Span.SetStatus(codes.Error, err.Error())
+}
+
+func (s Span) End() {
+	s.Span.End()
+}
+
+//keyValue - атрибут с типом значения; неизвестные типы записываются строкой.
+func keyValue(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	}
+	return attribute.String(key, fmt.Sprint(value))
+}
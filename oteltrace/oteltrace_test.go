@@ -0,0 +1,76 @@
+//go:build otel
+
+package oteltrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/soluchok/marketapi"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpans(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"offers":[]}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	a := &marketapi.API{
+		Key:          "test-oteltrace",
+		URL:          server.URL,
+		Lang:         "ru",
+		RateInterval: time.Millisecond,
+		Retries:      1,
+		RetryDelay:   time.Millisecond,
+		Tracer:       New(provider.Tracer("marketapi")),
+	}
+	if _, err := a.ItemInfo("57939770", "57939888"); err != nil {
+		t.Fatal(err)
+	}
+
+	var request sdktrace.ReadOnlySpan
+	attempts := 0
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "marketapi.ItemInfo":
+			request = span
+		case "marketapi.attempt":
+			attempts++
+		}
+	}
+	if attempts != 2 {
+		t.Errorf("attempt spans = %d, want 2", attempts)
+	}
+	if request == nil {
+		t.Fatal("no marketapi.ItemInfo span")
+	}
+	want := map[attribute.Key]attribute.Value{
+		"classid":          attribute.StringValue("57939770"),
+		"instanceid":       attribute.StringValue("57939888"),
+		"retries":          attribute.IntValue(1),
+		"result":           attribute.StringValue("ok"),
+		"http.status_code": attribute.IntValue(200),
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range request.Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("attribute %s = %v, want %v", key, got[key].Emit(), value.Emit())
+		}
+	}
+}
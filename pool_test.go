@@ -0,0 +1,43 @@
+package marketapi
+
+import "testing"
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("TESTPOOL_ACCOUNT_MAIN", "csgo:main-key")
+	t.Setenv("TESTPOOL_ACCOUNT_DOTA", "dota2:dota-key")
+	t.Setenv("TESTPOOL_KEY", "not-an-account")
+	t.Setenv("TESTPOOL_PAY_PASS", "pass:with:colons")
+
+	pool := NewAccountPool()
+	if err := pool.LoadEnv("TESTPOOL_"); err != nil {
+		t.Fatal(err)
+	}
+	names := pool.Names()
+	if len(names) != 2 || names[0] != "dota" || names[1] != "main" {
+		t.Fatalf("Names() = %v, want [dota main]", names)
+	}
+	api, _ := pool.Get("main")
+	if api.Key.Reveal() != "main-key" || api.Action != ActCSGO {
+		t.Fatalf("main account = %s/%s, want main-key/%s", api.Key.Reveal(), api.Action, ActCSGO)
+	}
+}
+
+func TestLoadEnvMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"TESTBAD_ACCOUNT_MAIN", "no-game-separator"},
+		{"TESTBAD_ACCOUNT_MAIN", "chess:key"},
+		{"TESTBAD_ACCOUNT_", "csgo:key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			err := NewAccountPool().LoadEnv("TESTBAD_")
+			if err == nil || err.Error() != ErrAPIBadEnv {
+				t.Fatalf("LoadEnv error = %v, want %s", err, ErrAPIBadEnv)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package marketapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+//DefaultRateInterval - минимальный интервал между запросами с одним ключом, если API.RateInterval не задан.
+const DefaultRateInterval = 200 * time.Millisecond
+
+//rateLimiter - выдает моменты начала запросов не чаще одного раза в interval.
+type rateLimiter struct {
+	mutex sync.Mutex
+	next  time.Time
+}
+
+var (
+	limitersMutex = &sync.Mutex{}
+	limiters      = map[[sha256.Size]byte]*rateLimiter{} // по хешу ключа, чтобы не хранить ключи в памяти
+)
+
+//limiterFor - общий лимитер для всех API с одинаковым ключом.
+func limiterFor(key string) *rateLimiter {
+	sum := sha256.Sum256([]byte(key))
+	limitersMutex.Lock()
+	defer limitersMutex.Unlock()
+	l, ok := limiters[sum]
+	if !ok {
+		l = &rateLimiter{}
+		limiters[sum] = l
+	}
+	return l
+}
+
+//wait - ждет своей очереди и возвращает время ожидания.
+func (l *rateLimiter) wait(ctx context.Context, interval time.Duration) (time.Duration, error) {
+	l.mutex.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(interval)
+	l.mutex.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return delay, ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
@@ -0,0 +1,184 @@
+package marketapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+//RepriceStrategy - стратегия выбора новой цены (в копейках) для выставленного предмета.
+//Если вернуть 0, цена предмета не меняется.
+type RepriceStrategy interface {
+	Price(a *API, trade Trade, info APIItemInfo) (int64, error)
+}
+
+//RepriceFunc - функция, удовлетворяющая RepriceStrategy.
+type RepriceFunc func(a *API, trade Trade, info APIItemInfo) (int64, error)
+
+func (f RepriceFunc) Price(a *API, trade Trade, info APIItemInfo) (int64, error) {
+	return f(a, trade, info)
+}
+
+//Undercut - цена на step копеек ниже самого дешевого чужого предложения.
+func Undercut(step int64) RepriceStrategy {
+	return RepriceFunc(func(a *API, trade Trade, info APIItemInfo) (int64, error) {
+		var lowest int64
+		for _, offer := range info.Offers {
+			count, _ := strconv.ParseInt(offer.Count, 10, 64)
+			myCount, _ := strconv.ParseInt(offer.MyCount, 10, 64)
+			if count <= myCount {
+				continue
+			}
+			price, err := strconv.ParseInt(offer.Price, 10, 64)
+			if err != nil {
+				continue
+			}
+			if lowest == 0 || price < lowest {
+				lowest = price
+			}
+		}
+		if lowest == 0 {
+			return 0, nil
+		}
+		return lowest - step, nil
+	})
+}
+
+//FollowMedian - медиана цен последних продаж из ItemHistory.
+func FollowMedian() RepriceStrategy {
+	return RepriceFunc(func(a *API, trade Trade, info APIItemInfo) (int64, error) {
+		history, err := a.ItemHistory(trade.IClassID, trade.IInstanceID)
+		if err != nil {
+			return 0, err
+		}
+		series := history.Series()
+		if len(series) == 0 {
+			return 0, nil
+		}
+		return int64(Median(series) + 0.5), nil
+	})
+}
+
+//FloorPrice - минимальная цена продажи, при которой после комиссии fee (0.05 = 5%)
+//остается cost + margin (0.1 = 10%).
+func FloorPrice(cost int64, margin float64, fee float64) int64 {
+	return int64(float64(cost)*(1+margin)/(1-fee) + 0.5)
+}
+
+//RepriceChange - запись об изменении цены.
+type RepriceChange struct {
+	Time     time.Time
+	ItemID   string
+	Name     string
+	OldPrice int64
+	NewPrice int64
+	DryRun   bool
+	Err      error
+}
+
+//Repricer - периодически пересчитывает цены предметов со статусом 1 (выставлен на продажу).
+type Repricer struct {
+	API      *API
+	Strategy RepriceStrategy
+	Floor    func(trade Trade) int64 // минимальная цена предмета, 0 - без ограничения
+	Interval time.Duration           // период между проходами, по умолчанию 5 минут
+	Delay    time.Duration           // пауза между вызовами SetPrice
+	DryRun   bool                    // только записывать изменения в Audit
+	Audit    func(change RepriceChange)
+}
+
+//RepriceOnce - один проход по всем выставленным предметам.
+func (r *Repricer) RepriceOnce() ([]RepriceChange, error) {
+	return r.RepriceOnceContext(r.API.context())
+}
+
+//RepriceOnceContext - RepriceOnce со спаном marketapi.Reprice на каждый предмет и запросами с ctx.
+func (r *Repricer) RepriceOnceContext(ctx context.Context) ([]RepriceChange, error) {
+	api := r.API.WithContext(ctx)
+	trades, err := api.Trades()
+	if err != nil {
+		return nil, err
+	}
+	var changes []RepriceChange
+	for _, trade := range trades {
+		if trade.UIStatus != "1" {
+			continue
+		}
+		change, err := r.reprice(ctx, trade)
+		if err != nil {
+			return changes, err
+		}
+		if change == nil {
+			continue
+		}
+		if r.Audit != nil {
+			r.Audit(*change)
+		}
+		changes = append(changes, *change)
+		if !r.DryRun && r.Delay > 0 {
+			if err := sleep(ctx, r.Delay); err != nil {
+				return changes, err
+			}
+		}
+	}
+	return changes, nil
+}
+
+//reprice - новая цена одного предмета, nil - цена не меняется.
+func (r *Repricer) reprice(ctx context.Context, trade Trade) (change *RepriceChange, err error) {
+	ctx, span := r.API.startSpan(ctx, "marketapi.Reprice",
+		"classid", trade.IClassID,
+		"instanceid", trade.IInstanceID,
+		"item_id", trade.UIID,
+	)
+	defer func() {
+		if change != nil {
+			span.SetAttribute("price", change.NewPrice)
+			if change.Err != nil {
+				span.RecordError(change.Err)
+			}
+		}
+		endSpan(span, err)
+	}()
+
+	api := r.API.WithContext(ctx)
+	info, err := api.ItemInfo(trade.IClassID, trade.IInstanceID)
+	if err != nil {
+		return nil, err
+	}
+	price, err := r.Strategy.Price(api, trade, info)
+	if err != nil {
+		return nil, err
+	}
+	if r.Floor != nil {
+		if floor := r.Floor(trade); price != 0 && price < floor {
+			price = floor
+		}
+	}
+	current := int64(trade.UIPrice*100 + 0.5)
+	if price <= 0 || price == current {
+		return nil, nil
+	}
+
+	change = &RepriceChange{
+		Time:     time.Now(),
+		ItemID:   trade.UIID,
+		Name:     trade.IMarketHashName,
+		OldPrice: current,
+		NewPrice: price,
+		DryRun:   r.DryRun,
+	}
+	if !r.DryRun {
+		_, change.Err = api.SetPrice(trade.UIID, price)
+	}
+	return change, nil
+}
+
+//Run - запускает RepriceOnce каждые Interval до отмены ctx.
+func (r *Repricer) Run(ctx context.Context) error {
+	return runEvery(ctx, r.Interval, 5*time.Minute, func() {
+		if _, err := r.RepriceOnceContext(ctx); err != nil && r.Audit != nil {
+			r.Audit(RepriceChange{Time: time.Now(), Err: err})
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package marketapi
+
+import (
+	"context"
+	"time"
+)
+
+//runEvery - вызывает fn сразу и затем каждые interval (def, если interval не задан) до отмены ctx.
+func runEvery(ctx context.Context, interval time.Duration, def time.Duration, fn func()) error {
+	if interval <= 0 {
+		interval = def
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+//sleep - пауза d, прерываемая отменой ctx.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package marketapi
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+//Key - ключ API. При выводе через fmt (%s, %v, %q, %#v) печатается замаскированным,
+//настоящее значение возвращает только Reveal.
+type Key string
+
+func (k Key) String() string {
+	if len(k) <= 8 {
+		return "****"
+	}
+	return "****" + string(k[len(k)-4:])
+}
+
+func (k Key) GoString() string {
+	return `marketapi.Key("` + k.String() + `")`
+}
+
+//Reveal - настоящее значение ключа для подстановки в запрос.
+func (k Key) Reveal() string {
+	return string(k)
+}
+
+//SecretProvider - источник ключей по имени (переменные окружения, файлы, внешнее хранилище секретов).
+type SecretProvider interface {
+	Key(name string) (Key, error)
+}
+
+//EnvSecrets - ключ из переменной окружения name.
+type EnvSecrets struct{}
+
+func (EnvSecrets) Key(name string) (Key, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return "", errors.New(ErrAPINoKey)
+	}
+	return Key(value), nil
+}
+
+//FileSecrets - ключ из файла Dir/name (например, Docker или Kubernetes secrets).
+type FileSecrets struct {
+	Dir string
+}
+
+func (f FileSecrets) Key(name string) (Key, error) {
+	path := name
+	if f.Dir != "" {
+		path = strings.TrimRight(f.Dir, "/") + "/" + name
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", errors.New(ErrAPINoKey)
+	}
+	return Key(value), nil
+}
+
+var (
+	secretParams    = regexp.MustCompile(`(key|pay_pass)=[^&\s":]*`)
+	secretMoneySend = regexp.MustCompile(`(money-send/\d+/)[^/?&\s":]*`)
+)
+
+//Redact - убирает ключ API, платежный пароль и ключ получателя MoneySend из строки
+//(например, из текста ошибки с URL запроса).
+func (a *API) Redact(s string) string {
+	if a.Key != "" {
+		s = strings.Replace(s, a.Key.Reveal(), a.Key.String(), -1)
+	}
+	s = secretMoneySend.ReplaceAllString(s, "${1}****")
+	return secretParams.ReplaceAllString(s, "$1=****")
+}
+
+//redactError - ошибка с тем же текстом, но без секретов.
+func (a *API) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(a.Redact(err.Error()))
+}
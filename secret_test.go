@@ -0,0 +1,79 @@
+package marketapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestKeyFormatting(t *testing.T) {
+	key := Key("O3HJPmn98Q3Gkf0ujED3ZH479w625Zy")
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "****25Zy"},
+		{"%v", "****25Zy"},
+		{"%q", `"****25Zy"`},
+		{"%#v", `marketapi.Key("****25Zy")`},
+		{"%+v", "****25Zy"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, key); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got := fmt.Sprintf("%v", struct{ Key Key }{key}); strings.Contains(got, key.Reveal()) {
+		t.Errorf("key leaked in struct output %q", got)
+	}
+	if got := Key("short").String(); got != "****" {
+		t.Errorf("short key String() = %q, want ****", got)
+	}
+	if key.Reveal() != "O3HJPmn98Q3Gkf0ujED3ZH479w625Zy" {
+		t.Errorf("Reveal() = %q", key.Reveal())
+	}
+}
+
+func TestRedact(t *testing.T) {
+	a := &API{Key: "O3HJPmn98Q3Gkf0ujED3ZH479w625Zy"}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			"url error",
+			`Get "https://market.csgo.com/api/Trades/?key=O3HJPmn98Q3Gkf0ujED3ZH479w625Zy": EOF`,
+			`Get "https://market.csgo.com/api/Trades/?key=****": EOF`,
+		},
+		{
+			"other key in query",
+			`Get https://market.csgo.com/api/Trades/?key=OTHERKEY123: EOF`,
+			`Get https://market.csgo.com/api/Trades/?key=****: EOF`,
+		},
+		{
+			"key before other params",
+			`https://market.csgo.com/api/v2/items?key=OTHERKEY123&page=2`,
+			`https://market.csgo.com/api/v2/items?key=****&page=2`,
+		},
+		{
+			"money send",
+			`Get "https://market.csgo.com/api/v2/money-send/1000/RECIPIENTKEY42?pay_pass=secret&key=OTHERKEY123": dial tcp: timeout`,
+			`Get "https://market.csgo.com/api/v2/money-send/1000/****?pay_pass=****&key=****": dial tcp: timeout`,
+		},
+		{
+			"bare key in text",
+			`invalid key O3HJPmn98Q3Gkf0ujED3ZH479w625Zy`,
+			`invalid key ****25Zy`,
+		},
+	}
+	for _, tt := range tests {
+		if got := a.Redact(tt.in); got != tt.want {
+			t.Errorf("%s: Redact() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if err := a.redactError(errors.New(tests[0].in)); err.Error() != tests[0].want {
+		t.Errorf("redactError() = %q, want %q", err, tests[0].want)
+	}
+}
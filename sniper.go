@@ -0,0 +1,144 @@
+package marketapi
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+//SnipeDecision - решение Sniper по одному предмету из QuickItems.
+type SnipeDecision struct {
+	Time   time.Time
+	Item   Item
+	Price  int64 // LPaid в копейках
+	Fair   int64 // справедливая цена в копейках, 0 - не считалась
+	Bought bool
+	Reason string
+	Err    error
+}
+
+//Sniper - опрашивает QuickItems и покупает через QuickBuy предметы, которые проходят фильтры
+//и стоят не дороже MaxRatio от справедливой цены, пока не потрачен Budget.
+//По умолчанию справедливая цена - Average из ItemHistory.
+type Sniper struct {
+	API       *API
+	Interval  time.Duration  // по умолчанию 10 секунд
+	Budget    int64          // в копейках
+	MaxRatio  float64        // 0.8 - покупать не дороже 80% справедливой цены, по умолчанию 0.8
+	Names     *regexp.Regexp // nil - любые названия
+	Tags      []string       // internal_name тегов, которые должны быть у предмета
+	FairValue func(item Item) (int64, error)
+	Log       func(decision SnipeDecision)
+
+	spent int64
+}
+
+//Spent - сколько уже потрачено в копейках.
+func (s *Sniper) Spent() int64 {
+	return s.spent
+}
+
+//maxRatio - MaxRatio или 0.8, если он не задан.
+func (s *Sniper) maxRatio() float64 {
+	if s.MaxRatio <= 0 {
+		return 0.8
+	}
+	return s.MaxRatio
+}
+
+func (s *Sniper) fairValue(item Item) (int64, error) {
+	if s.FairValue != nil {
+		return s.FairValue(item)
+	}
+	history, err := s.API.ItemHistory(item.IClassID, item.IInstanceID)
+	if err != nil {
+		return 0, err
+	}
+	return history.Average, nil
+}
+
+func hasTags(item Item, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range item.ITags {
+			if tag.InternalName == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Sniper) decide(item Item) SnipeDecision {
+	decision := SnipeDecision{Time: time.Now(), Item: item}
+	price, err := strconv.ParseInt(item.LPaid, 10, 64)
+	if err != nil {
+		decision.Reason = "bad price"
+		decision.Err = err
+		return decision
+	}
+	decision.Price = price
+	if s.Names != nil && !s.Names.MatchString(item.IMarketHashName) {
+		decision.Reason = "name does not match"
+		return decision
+	}
+	if !hasTags(item, s.Tags) {
+		decision.Reason = "tags do not match"
+		return decision
+	}
+	if s.spent+price > s.Budget {
+		decision.Reason = "over budget"
+		return decision
+	}
+	decision.Fair, decision.Err = s.fairValue(item)
+	if decision.Err != nil {
+		decision.Reason = "no fair value"
+		return decision
+	}
+	if decision.Fair <= 0 || float64(price) > float64(decision.Fair)*s.maxRatio() {
+		decision.Reason = "too expensive"
+		return decision
+	}
+
+	buy, err := s.API.QuickBuy(item.UIID)
+	if err != nil || !buy.Success {
+		decision.Reason = "quick buy failed"
+		decision.Err = err
+		return decision
+	}
+	s.spent += price
+	decision.Bought = true
+	decision.Reason = "bought"
+	return decision
+}
+
+//SnipeOnce - один опрос QuickItems.
+func (s *Sniper) SnipeOnce() ([]SnipeDecision, error) {
+	items, err := s.API.QuickItems()
+	if err != nil {
+		return nil, err
+	}
+	decisions := make([]SnipeDecision, 0, len(items.Items))
+	for _, item := range items.Items {
+		decision := s.decide(item)
+		if s.Log != nil {
+			s.Log(decision)
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
+
+//Run - вызывает SnipeOnce каждые Interval до отмены ctx.
+func (s *Sniper) Run(ctx context.Context) error {
+	return runEvery(ctx, s.Interval, 10*time.Second, func() {
+		if _, err := s.SnipeOnce(); err != nil && s.Log != nil {
+			s.Log(SnipeDecision{Time: time.Now(), Reason: "quick items failed", Err: err})
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package marketapi
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//PricePoint - одна продажа из ItemHistory.
+type PricePoint struct {
+	Time  time.Time
+	Price int64 // в копейках
+}
+
+//Candle - свеча OHLC за интервал, начинающийся в Time.
+type Candle struct {
+	Time   time.Time
+	Open   int64
+	High   int64
+	Low    int64
+	Close  int64
+	Volume int
+}
+
+//PeriodVolume - число продаж за интервал, начинающийся в Time.
+type PeriodVolume struct {
+	Time   time.Time
+	Volume int
+}
+
+//PriceStats - статистика по продажам. Mean - средняя цена, она же VWAP, так как каждая продажа - один предмет.
+//Slope - копеек в сутки, см. TrendSlope.
+type PriceStats struct {
+	Count      int
+	Min        int64
+	Max        int64
+	Mean       float64
+	Median     float64
+	P10        float64
+	P90        float64
+	Volatility float64
+	Slope      float64
+}
+
+//Series - продажи из History в виде отсортированного по времени ряда. Записи, которые не удалось разобрать, пропускаются.
+func (h APIItemHistory) Series() []PricePoint {
+	points := make([]PricePoint, 0, len(h.History))
+	for _, history := range h.History {
+		price, err := strconv.ParseInt(history.LPrice, 10, 64)
+		if err != nil {
+			continue
+		}
+		ts, err := strconv.ParseInt(history.LTime, 10, 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, PricePoint{Time: time.Unix(ts, 0), Price: price})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points
+}
+
+func sortedPrices(points []PricePoint) []float64 {
+	prices := make([]float64, len(points))
+	for i, point := range points {
+		prices[i] = float64(point.Price)
+	}
+	sort.Float64s(prices)
+	return prices
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 || math.IsNaN(p) {
+		return 0
+	}
+	p = math.Max(0, math.Min(100, p))
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+//Percentile - p-й перцентиль цен с линейной интерполяцией. p вне 0-100 приводится к границе, для NaN - 0.
+func Percentile(points []PricePoint, p float64) float64 {
+	return percentile(sortedPrices(points), p)
+}
+
+//Median - медиана цен.
+func Median(points []PricePoint) float64 {
+	return Percentile(points, 50)
+}
+
+//VWAP - средняя цена, взвешенная по объему. Каждая продажа в ItemHistory - один предмет,
+//поэтому VWAP совпадает со средней ценой PriceStats.Mean.
+func VWAP(points []PricePoint) float64 {
+	if len(points) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, point := range points {
+		sum += float64(point.Price)
+	}
+	return sum / float64(len(points))
+}
+
+//Volatility - стандартное отклонение логарифмических доходностей между соседними продажами.
+func Volatility(points []PricePoint) float64 {
+	var returns []float64
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Price > 0 && points[i].Price > 0 {
+			returns = append(returns, math.Log(float64(points[i].Price)/float64(points[i-1].Price)))
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(variance / float64(len(returns)-1))
+}
+
+//TrendSlope - наклон линейной регрессии цены по времени, копеек в сутки.
+func TrendSlope(points []PricePoint) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	start := points[0].Time
+	var sx, sy, sxx, sxy float64
+	for _, point := range points {
+		x := point.Time.Sub(start).Hours() / 24
+		y := float64(point.Price)
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	n := float64(len(points))
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / d
+}
+
+//Candles - свечи OHLC с интервалом interval. Интервалы без продаж пропускаются.
+//points должны быть отсортированы по времени, как после Series.
+func Candles(points []PricePoint, interval time.Duration) []Candle {
+	var candles []Candle
+	for _, point := range points {
+		start := point.Time.Truncate(interval)
+		if n := len(candles); n > 0 && candles[n-1].Time.Equal(start) {
+			c := &candles[n-1]
+			if point.Price > c.High {
+				c.High = point.Price
+			}
+			if point.Price < c.Low {
+				c.Low = point.Price
+			}
+			c.Close = point.Price
+			c.Volume++
+			continue
+		}
+		candles = append(candles, Candle{
+			Time:   start,
+			Open:   point.Price,
+			High:   point.Price,
+			Low:    point.Price,
+			Close:  point.Price,
+			Volume: 1,
+		})
+	}
+	return candles
+}
+
+//Volume - число продаж за каждый интервал interval. Интервалы без продаж пропускаются.
+//points должны быть отсортированы по времени, как после Series.
+func Volume(points []PricePoint, interval time.Duration) []PeriodVolume {
+	candles := Candles(points, interval)
+	volumes := make([]PeriodVolume, len(candles))
+	for i, c := range candles {
+		volumes[i] = PeriodVolume{Time: c.Time, Volume: c.Volume}
+	}
+	return volumes
+}
+
+//Stats - сводная статистика по ряду продаж.
+func Stats(points []PricePoint) PriceStats {
+	if len(points) == 0 {
+		return PriceStats{}
+	}
+	sorted := sortedPrices(points)
+	return PriceStats{
+		Count:      len(points),
+		Min:        int64(sorted[0]),
+		Max:        int64(sorted[len(sorted)-1]),
+		Mean:       VWAP(points),
+		Median:     percentile(sorted, 50),
+		P10:        percentile(sorted, 10),
+		P90:        percentile(sorted, 90),
+		Volatility: Volatility(points),
+		Slope:      TrendSlope(points),
+	}
+}
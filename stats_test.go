@@ -0,0 +1,129 @@
+package marketapi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var statsStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func pricePoints(prices ...int64) []PricePoint {
+	points := make([]PricePoint, len(prices))
+	for i, price := range prices {
+		points[i] = PricePoint{Time: statsStart.Add(time.Duration(i) * 24 * time.Hour), Price: price}
+	}
+	return points
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []int64
+		p      float64
+		want   float64
+	}{
+		{"empty", nil, 50, 0},
+		{"single", []int64{100}, 90, 100},
+		{"median odd", []int64{300, 100, 200}, 50, 200},
+		{"median even", []int64{100, 200, 300, 400}, 50, 250},
+		{"interpolated", []int64{100, 200, 300, 400, 500}, 10, 140},
+		{"min", []int64{100, 200, 300}, 0, 100},
+		{"max", []int64{100, 200, 300}, 100, 300},
+		{"below range", []int64{100, 200, 300}, -10, 100},
+		{"above range", []int64{100, 200, 300}, 150, 300},
+		{"NaN", []int64{100, 200, 300}, math.NaN(), 0},
+	}
+	for _, tt := range tests {
+		if got := Percentile(pricePoints(tt.prices...), tt.p); got != tt.want {
+			t.Errorf("%s: Percentile(%v, %v) = %v, want %v", tt.name, tt.prices, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestCandles(t *testing.T) {
+	at := func(d time.Duration, price int64) PricePoint {
+		return PricePoint{Time: statsStart.Add(d), Price: price}
+	}
+	tests := []struct {
+		name   string
+		points []PricePoint
+		want   []Candle
+	}{
+		{"empty", nil, nil},
+		{"one interval", []PricePoint{
+			at(0, 200), at(10*time.Minute, 300), at(20*time.Minute, 100), at(59*time.Minute, 150),
+		}, []Candle{
+			{Time: statsStart, Open: 200, High: 300, Low: 100, Close: 150, Volume: 4},
+		}},
+		{"gap skipped", []PricePoint{
+			at(0, 200), at(30*time.Minute, 250), at(3*time.Hour+5*time.Minute, 400),
+		}, []Candle{
+			{Time: statsStart, Open: 200, High: 250, Low: 200, Close: 250, Volume: 2},
+			{Time: statsStart.Add(3 * time.Hour), Open: 400, High: 400, Low: 400, Close: 400, Volume: 1},
+		}},
+	}
+	for _, tt := range tests {
+		got := Candles(tt.points, time.Hour)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Candles = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Time.Equal(tt.want[i].Time) || got[i].Open != tt.want[i].Open || got[i].High != tt.want[i].High ||
+				got[i].Low != tt.want[i].Low || got[i].Close != tt.want[i].Close || got[i].Volume != tt.want[i].Volume {
+				t.Errorf("%s: Candles[%d] = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestVolume(t *testing.T) {
+	tests := []struct {
+		name     string
+		points   []PricePoint
+		interval time.Duration
+		want     []int
+	}{
+		{"empty", nil, 24 * time.Hour, nil},
+		{"daily", pricePoints(100, 200, 300), 24 * time.Hour, []int{1, 1, 1}},
+		{"weekly", pricePoints(100, 200, 300), 7 * 24 * time.Hour, []int{3}},
+		{"gap skipped", []PricePoint{
+			{Time: statsStart, Price: 100},
+			{Time: statsStart.Add(time.Hour), Price: 100},
+			{Time: statsStart.Add(5 * 24 * time.Hour), Price: 100},
+		}, 24 * time.Hour, []int{2, 1}},
+	}
+	for _, tt := range tests {
+		got := Volume(tt.points, tt.interval)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Volume = %v, want volumes %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i].Volume != tt.want[i] {
+				t.Errorf("%s: Volume[%d] = %d, want %d", tt.name, i, got[i].Volume, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestTrendSlope(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []PricePoint
+		want   float64
+	}{
+		{"empty", nil, 0},
+		{"single", pricePoints(100), 0},
+		{"flat", pricePoints(100, 100, 100), 0},
+		{"rising", pricePoints(100, 200, 300), 100},
+		{"falling", pricePoints(300, 250, 200, 150), -50},
+		{"same time", []PricePoint{{Time: statsStart, Price: 100}, {Time: statsStart, Price: 200}}, 0},
+	}
+	for _, tt := range tests {
+		if got := TrendSlope(tt.points); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: TrendSlope = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package marketapi
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+var tokenRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{8}$`)
+
+//ValidToken - проверка формата токена из ссылки на обмен Steam.
+func ValidToken(token string) bool {
+	return tokenRegexp.MatchString(token)
+}
+
+//ParseTradeURL - разбор ссылки на обмен вида https://steamcommunity.com/tradeoffer/new/?partner=123&token=AbCdEfGh
+func ParseTradeURL(rawurl string) (TradeURL, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host != "steamcommunity.com" || u.Path != "/tradeoffer/new/" && u.Path != "/tradeoffer/new" {
+		return TradeURL{}, errors.New(ErrAPIBadURL)
+	}
+	partner, err := strconv.ParseUint(u.Query().Get("partner"), 10, 32)
+	if err != nil || partner == 0 {
+		return TradeURL{}, errors.New(ErrAPIBadURL)
+	}
+	token := u.Query().Get("token")
+	if !ValidToken(token) {
+		return TradeURL{}, errors.New(ErrAPIBadToken)
+	}
+	return TradeURL{Partner: partner, Token: token}, nil
+}
+
+//String - ссылка на обмен.
+func (t TradeURL) String() string {
+	return "https://steamcommunity.com/tradeoffer/new/?partner=" + strconv.FormatUint(t.Partner, 10) + "&token=" + t.Token
+}
+
+//CheckToken - Сравнивает установленный на маркете токен с токеном из tradeURL.
+//Если Test() сообщает, что токен не установлен (UserToken = false), или токены отличаются,
+//заново устанавливает токен через SetToken.
+func (a *API) CheckToken(tradeURL TradeURL) (APITokenCheck, error) {
+	check := APITokenCheck{Configured: tradeURL.Token}
+	test, err := a.Test()
+	if err != nil {
+		return check, err
+	}
+	check.UserToken = test.Status.UserToken
+	token, err := a.GetToken()
+	if err != nil {
+		return check, err
+	}
+	check.Stored = token.Token
+	check.Mismatch = check.Stored != check.Configured
+
+	if check.UserToken && !check.Mismatch {
+		return check, nil
+	}
+	if _, err := a.SetToken(tradeURL.Token); err != nil {
+		return check, err
+	}
+	check.Reset = true
+	return check, nil
+}
@@ -0,0 +1,63 @@
+package marketapi
+
+import (
+	"context"
+	"strings"
+)
+
+//Tracer - источник спанов. Интерфейс повторяет нужную часть trace.Tracer из OpenTelemetry,
+//адаптер к нему пишется в несколько строк: attrs превращаются в attribute.KeyValue,
+//а Span оборачивает trace.Span.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, Span)
+}
+
+//Span - спан одной операции.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) RecordError(err error)                      {}
+func (noopSpan) End()                                       {}
+
+//startSpan - спан в a.Tracer, attrs - пары ключ, значение.
+func (a *API) startSpan(ctx context.Context, name string, attrs ...interface{}) (context.Context, Span) {
+	if a.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return a.Tracer.Start(ctx, name, attrs...)
+}
+
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+//requestResult - атрибут result спана запроса: ok или класс ошибки, как в метриках (см. errorClass).
+func requestResult(err error, info requestInfo) string {
+	if err == nil {
+		return "ok"
+	}
+	return errorClass(err, info)
+}
+
+//requestPath - путь запроса без схемы, адреса и параметров.
+func requestPath(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	if i := strings.IndexByte(url, '/'); i >= 0 {
+		url = url[i:]
+	}
+	if i := strings.IndexByte(url, '?'); i >= 0 {
+		url = url[:i]
+	}
+	return url
+}
@@ -0,0 +1,236 @@
+package marketapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//TradeState - состояние предмета со страницы "Мои вещи".
+type TradeState int
+
+const (
+	TradeListed      TradeState = iota + 1 // UIStatus = 1
+	TradeSold                              // UIStatus = 2, нужно передать боту
+	TradeWaiting                           // UIStatus = 3, ждем передачи от продавца
+	TradeCollectable                       // UIStatus = 4, можно забрать
+	TradeRequested                         // вызван ItemRequest, ждем принятия трейда
+	TradeDone                              // предмет пропал из Trades
+)
+
+func (s TradeState) String() string {
+	switch s {
+	case TradeListed:
+		return "listed"
+	case TradeSold:
+		return "sold"
+	case TradeWaiting:
+		return "waiting"
+	case TradeCollectable:
+		return "collectable"
+	case TradeRequested:
+		return "requested"
+	case TradeDone:
+		return "done"
+	}
+	return "unknown"
+}
+
+func tradeState(status string) TradeState {
+	switch status {
+	case "1":
+		return TradeListed
+	case "2":
+		return TradeSold
+	case "3":
+		return TradeWaiting
+	case "4":
+		return TradeCollectable
+	}
+	return 0
+}
+
+//TradeItem - предмет и его текущее состояние.
+//Offer - ответ ItemRequest (номер трейда и секрет), если он был вызван.
+type TradeItem struct {
+	Trade     Trade
+	State     TradeState
+	Offer     APIItemRequest
+	Requested time.Time
+	Updated   time.Time
+
+	offerSeen bool // Offer был среди MarketTrades после последнего ItemRequest
+}
+
+//TradeManager - следит за Trades и вызывает ItemRequest для проданных (передать боту)
+//и купленных (забрать у бота) предметов.
+type TradeManager struct {
+	API          *API
+	Interval     time.Duration // период опроса Trades, по умолчанию 30 секунд
+	Retry        time.Duration // через сколько повторить ItemRequest, если трейд не принят, по умолчанию 5 минут
+	OnTransition func(item TradeItem, from TradeState)
+	OnError      func(err error)
+
+	mutex sync.Mutex
+	items map[string]*TradeItem
+}
+
+//retry - Retry или 5 минут, если он не задан.
+func (m *TradeManager) retry() time.Duration {
+	if m.Retry <= 0 {
+		return 5 * time.Minute
+	}
+	return m.Retry
+}
+
+//Items - текущие состояния всех отслеживаемых предметов.
+func (m *TradeManager) Items() []TradeItem {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	result := make([]TradeItem, 0, len(m.items))
+	for _, item := range m.items {
+		result = append(result, *item)
+	}
+	return result
+}
+
+//transition - смена состояния, OnTransition для нее вызывается без m.mutex.
+type transition struct {
+	item TradeItem
+	from TradeState
+}
+
+//setState - меняет состояние item и добавляет смену в transitions. Вызывается под m.mutex.
+func (m *TradeManager) setState(transitions []transition, item *TradeItem, state TradeState) []transition {
+	if item.State == state {
+		return transitions
+	}
+	from := item.State
+	item.State = state
+	item.Updated = time.Now()
+	return append(transitions, transition{*item, from})
+}
+
+func (m *TradeManager) notify(transitions []transition) {
+	if m.OnTransition == nil {
+		return
+	}
+	for _, t := range transitions {
+		m.OnTransition(t.item, t.from)
+	}
+}
+
+type tradeRequest struct {
+	dir   ItemRequestDir
+	botid string
+}
+
+//Poll - один опрос Trades и MarketTrades и вызов ItemRequest там, где это нужно.
+//ItemRequest повторяется, если его трейд пропал из MarketTrades (отклонен или истек) или прошло Retry.
+//ItemRequest и OnTransition вызываются без блокировки, из OnTransition можно вызывать Items.
+func (m *TradeManager) Poll() error {
+	trades, err := m.API.Trades()
+	if err != nil {
+		return err
+	}
+	market, err := m.API.MarketTrades()
+	if err != nil {
+		return err
+	}
+	active := map[string]bool{}
+	for _, trade := range market.Trades {
+		active[trade.TradeID] = true
+	}
+
+	transitions, pending := m.update(trades, active)
+	m.notify(transitions)
+
+	for key, ids := range pending {
+		offer, err := m.API.ItemRequest(key.dir, key.botid)
+		if err != nil {
+			if m.OnError != nil {
+				m.OnError(err)
+			}
+			continue
+		}
+		m.notify(m.requested(ids, offer))
+	}
+	return nil
+}
+
+//update - новые состояния предметов из trades и предметы, для которых нужен ItemRequest.
+//active - trade_id активных трейдов из MarketTrades.
+func (m *TradeManager) update(trades []Trade, active map[string]bool) ([]transition, map[tradeRequest][]string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.items == nil {
+		m.items = map[string]*TradeItem{}
+	}
+
+	var transitions []transition
+	seen := map[string]bool{}
+	pending := map[tradeRequest][]string{}
+	for _, trade := range trades {
+		seen[trade.UIID] = true
+		state := tradeState(trade.UIStatus)
+		item, ok := m.items[trade.UIID]
+		if !ok {
+			item = &TradeItem{}
+			m.items[trade.UIID] = item
+		}
+		item.Trade = trade
+		if item.State == TradeRequested && state != TradeListed && state != TradeWaiting {
+			if active[item.Offer.Trade] {
+				item.offerSeen = true
+			}
+			gone := item.offerSeen && !active[item.Offer.Trade]
+			if !gone && time.Since(item.Requested) < m.retry() {
+				continue
+			}
+		}
+		transitions = m.setState(transitions, item, state)
+		switch state {
+		case TradeSold:
+			key := tradeRequest{ItemRequestIn, "1"}
+			pending[key] = append(pending[key], trade.UIID)
+		case TradeCollectable:
+			key := tradeRequest{ItemRequestOut, trade.UIBid}
+			pending[key] = append(pending[key], trade.UIID)
+		}
+	}
+
+	for id, item := range m.items {
+		if !seen[id] {
+			transitions = m.setState(transitions, item, TradeDone)
+			delete(m.items, id)
+		}
+	}
+	return transitions, pending
+}
+
+//requested - переводит предметы ids в TradeRequested после успешного ItemRequest.
+func (m *TradeManager) requested(ids []string, offer APIItemRequest) []transition {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var transitions []transition
+	for _, id := range ids {
+		item, ok := m.items[id]
+		if !ok {
+			continue
+		}
+		item.Offer = offer
+		item.Requested = time.Now()
+		item.offerSeen = false
+		transitions = m.setState(transitions, item, TradeRequested)
+	}
+	return transitions
+}
+
+//Run - вызывает Poll каждые Interval до отмены ctx.
+func (m *TradeManager) Run(ctx context.Context) error {
+	return runEvery(ctx, m.Interval, 30*time.Second, func() {
+		if err := m.Poll(); err != nil && m.OnError != nil {
+			m.OnError(err)
+		}
+	})
+}
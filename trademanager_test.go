@@ -0,0 +1,96 @@
+package marketapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTradeManagerItemsFromOnTransition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/Trades/"):
+			w.Write([]byte(`[{"ui_id":"100","ui_status":"2","i_classid":"1","i_instanceid":"0"}]`))
+		case strings.HasPrefix(r.URL.Path, "/api/MarketTrades/"):
+			w.Write([]byte(`{"success":true,"trades":[]}`))
+		case strings.HasPrefix(r.URL.Path, "/api/ItemRequest/"):
+			w.Write([]byte(`{"success":true,"trade":"555","nick":"bot","botid":1,"profile":"","secret":"AB12"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var states []TradeState
+	m := &TradeManager{API: &API{Key: "test-trademanager", URL: server.URL, RateInterval: time.Millisecond}}
+	m.OnTransition = func(item TradeItem, from TradeState) {
+		items := m.Items()
+		if len(items) != 1 {
+			t.Errorf("Items() = %d items, want 1", len(items))
+		}
+		states = append(states, item.State)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- m.Poll() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Poll deadlocked when OnTransition called Items")
+	}
+
+	want := []TradeState{TradeSold, TradeRequested}
+	if len(states) != len(want) || states[0] != want[0] || states[1] != want[1] {
+		t.Fatalf("transitions = %v, want %v", states, want)
+	}
+	if offer := m.Items()[0].Offer; offer.TradeOfferID != 555 {
+		t.Fatalf("Offer.TradeOfferID = %d, want 555", offer.TradeOfferID)
+	}
+}
+
+func TestTradeManagerRequestsAgainWhenOfferGone(t *testing.T) {
+	var requests, offerActive int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/Trades/"):
+			w.Write([]byte(`[{"ui_id":"100","ui_status":"2","i_classid":"1","i_instanceid":"0"}]`))
+		case strings.HasPrefix(r.URL.Path, "/api/MarketTrades/"):
+			if atomic.LoadInt32(&offerActive) == 1 {
+				w.Write([]byte(`{"success":true,"trades":[{"dir":"in","trade_id":"555","bot_id":"1"}]}`))
+				return
+			}
+			w.Write([]byte(`{"success":true,"trades":[]}`))
+		case strings.HasPrefix(r.URL.Path, "/api/ItemRequest/"):
+			atomic.AddInt32(&requests, 1)
+			w.Write([]byte(`{"success":true,"trade":"555","nick":"bot","botid":1,"profile":"","secret":"AB12"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	m := &TradeManager{API: &API{Key: "test-trademanager-gone", URL: server.URL, RateInterval: time.Millisecond}}
+	poll := func(active int32, want int32) {
+		t.Helper()
+		atomic.StoreInt32(&offerActive, active)
+		if err := m.Poll(); err != nil {
+			t.Fatal(err)
+		}
+		if got := atomic.LoadInt32(&requests); got != want {
+			t.Fatalf("ItemRequest calls = %d, want %d", got, want)
+		}
+	}
+
+	poll(0, 1) //продано - первый ItemRequest
+	poll(1, 1) //трейд активен - ждем
+	poll(0, 2) //трейд пропал - ItemRequest еще раз
+	if state := m.Items()[0].State; state != TradeRequested {
+		t.Fatalf("State = %v, want %v", state, TradeRequested)
+	}
+}
@@ -1,5 +1,11 @@
 package marketapi
 
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
 type APIItemDBCurrent struct {
 	Time int64  `json:"time"`
 	DB   string `json:"db"`
@@ -22,7 +28,7 @@ type CsvLine struct {
 	CCraftable    string //tf2
 	CLook         string //tf2
 	CCollection   string //tf2
-	CMarketName   string
+	CMarketName   string //market_hash_name
 	CNameColor    string
 	CPriceUpdated string
 	CPop          string
@@ -87,8 +93,27 @@ type APIItemHistory struct {
 	History []History `json:"history"`
 }
 
+type MarketTradeItem struct {
+	UIID           string `json:"ui_id"`
+	ClassID        string `json:"classid"`
+	InstanceID     string `json:"instanceid"`
+	MarketHashName string `json:"market_hash_name"`
+	Price          int64  `json:"price"`
+}
+
+type MarketTrade struct {
+	Dir       string            `json:"dir"` // in - передать боту, out - забрать у бота
+	TradeID   string            `json:"trade_id"`
+	BotID     string            `json:"bot_id"`
+	Nick      string            `json:"nick"`
+	Secret    string            `json:"secret"`
+	Timestamp int64             `json:"timestamp"`
+	Items     []MarketTradeItem `json:"items"`
+}
+
 type APIMarketTrades struct {
-	//TODO
+	Success bool          `json:"success"`
+	Trades  []MarketTrade `json:"trades"`
 }
 
 type Trade struct {
@@ -146,14 +171,23 @@ type APIPingPong struct {
 	Ping    string `json:"ping"`
 	Success bool   `json:"success"`
 }
+type ItemRequestItem struct {
+	UIID           string `json:"ui_id"`
+	AssetID        string `json:"assetid"`
+	ClassID        string `json:"classid"`
+	InstanceID     string `json:"instanceid"`
+	MarketHashName string `json:"market_hash_name"`
+}
+
 type APIItemRequest struct {
-	Success bool   `json:"success"`
-	Trade   string `json:"trade"`
-	Nick    string `json:"nick"`
-	Botid   int64  `json:"botid"`
-	Profile string `json:"profile"`
-	Secret  string `json:"secret"`
-	Items   interface{}
+	Success      bool              `json:"success"`
+	Trade        string            `json:"trade"`
+	Nick         string            `json:"nick"`
+	Botid        int64             `json:"botid"`
+	Profile      string            `json:"profile"`
+	Secret       string            `json:"secret"`
+	Items        []ItemRequestItem `json:"items"`
+	TradeOfferID uint64            `json:"-"` // Trade, разобранный в число
 }
 
 type OHistory struct {
@@ -223,21 +257,21 @@ type APISetToken struct {
 }
 
 type Item struct {
-	UIID                string `json:"ui_id"`
-	LPaid               string `json:"l_paid"`
-	IClassID            string `json:"i_classid"`
-	IInstanceID         string `json:"i_instanceid"`
-	IMarketHashName     string `json:"i_market_hash_name"`
-	IRarity             string `json:"i_rarity"`
-	IMarket_name        string `json:"i_market_name"`
-	IName               string `json:"i_name"`
-	IQuality            string `json:"i_quality"`
-	INameColor          string `json:"i_name_color"`
-	HEName              string `json:"he_name"`
-	IDescriptionsString string `json:"i_descriptions"`
-	ITagsString         string `json:"i_tags"`
-	IDescriptions       []Description
-	ITags               []Tag
+	UIID                string        `json:"ui_id"`
+	LPaid               string        `json:"l_paid"`
+	IClassID            string        `json:"i_classid"`
+	IInstanceID         string        `json:"i_instanceid"`
+	IMarketHashName     string        `json:"i_market_hash_name"`
+	IRarity             string        `json:"i_rarity"`
+	IMarket_name        string        `json:"i_market_name"`
+	IName               string        `json:"i_name"`
+	IQuality            string        `json:"i_quality"`
+	INameColor          string        `json:"i_name_color"`
+	HEName              string        `json:"he_name"`
+	IDescriptionsString string        `json:"i_descriptions"`
+	ITagsString         string        `json:"i_tags"`
+	IDescriptions       []Description `json:"-"`
+	ITags               []Tag         `json:"-"`
 }
 
 type APIQuickItems struct {
@@ -299,9 +333,199 @@ type APIGetWSAuth struct {
 }
 
 type API struct {
-	Key    string
-	Action string
-	URL    string
-	Lang   string // ru or en
-	Code   string
+	Key          Key
+	Action       string
+	URL          string
+	Lang         string // ru or en
+	Code         string
+	RateInterval time.Duration // минимальный интервал между запросами с этим ключом, по умолчанию DefaultRateInterval
+	Retries      int           // сколько раз повторять запрос при таймауте, ошибке сети или 5xx; методы, меняющие состояние, не повторяются
+	RetryDelay   time.Duration // пауза перед первым повтором, затем удваивается; по умолчанию DefaultRetryDelay
+	Cache        *Cache        // кэш ответов ItemInfo, ItemHistory, ItemDBCurrent; nil - без кэша
+	Client       *http.Client  // nil - http.DefaultClient
+	Logger       Logger        // nil - без логов
+	LogBodies    bool          // писать тела ответов в Logger на уровне Debug
+	Metrics      *Metrics      // nil - без метрик
+	Tracer       Tracer        // nil - без трассировки
+
+	ctx context.Context // см. WithContext
+}
+
+type V2Item struct {
+	ItemID         string  `json:"item_id"`
+	AssetID        string  `json:"assetid"`
+	ClassID        string  `json:"classid"`
+	InstanceID     string  `json:"instanceid"`
+	RealInstance   string  `json:"real_instance"`
+	MarketHashName string  `json:"market_hash_name"`
+	Position       int64   `json:"position"`
+	Price          float64 `json:"price"`
+	Currency       string  `json:"currency"`
+	Status         string  `json:"status"`
+	LiveTime       int64   `json:"live_time"`
+	BotID          string  `json:"botid"`
+}
+
+type APIV2Items struct {
+	Success bool     `json:"success"`
+	Items   []V2Item `json:"items"`
+}
+
+type APIV2AddToSale struct {
+	Success bool   `json:"success"`
+	ItemID  string `json:"item_id"`
+}
+
+type APIV2SetPrice struct {
+	Success bool `json:"success"`
+}
+
+type APIV2Buy struct {
+	Success bool   `json:"success"`
+	ID      string `json:"id"`
+}
+
+type V2SearchItem struct {
+	MarketHashName string `json:"market_hash_name"`
+	Price          int64  `json:"price"`
+	Class          int64  `json:"class"`
+	Instance       int64  `json:"instance"`
+	ID             int64  `json:"id"`
+}
+
+type APIV2SearchItemByHashName struct {
+	Success  bool           `json:"success"`
+	Currency string         `json:"currency"`
+	Data     []V2SearchItem `json:"data"`
+}
+
+type V2Price struct {
+	MarketHashName string `json:"market_hash_name"`
+	Volume         string `json:"volume"`
+	Price          string `json:"price"`
+}
+
+type APIV2Prices struct {
+	Success  bool      `json:"success"`
+	Time     int64     `json:"time"`
+	Currency string    `json:"currency"`
+	Items    []V2Price `json:"items"`
+}
+
+type V2ClassInstancePrice struct {
+	Price          string `json:"price"`
+	BuyOrder       string `json:"buy_order"`
+	AvgPrice       string `json:"avg_price"`
+	Popularity7d   string `json:"popularity_7d"`
+	MarketHashName string `json:"market_hash_name"`
+	RuName         string `json:"ru_name"`
+	Rarity         string `json:"rarity"`
+}
+
+type APIV2PricesClassInstance struct {
+	Success  bool                            `json:"success"`
+	Time     int64                           `json:"time"`
+	Currency string                          `json:"currency"`
+	Items    map[string]V2ClassInstancePrice `json:"items"`
+}
+
+type V2OfferItem struct {
+	AppID     int64  `json:"appid"`
+	ContextID int64  `json:"contextid"`
+	AssetID   string `json:"assetid"`
+	Amount    int64  `json:"amount"`
+}
+
+type V2Offer struct {
+	Partner           int64         `json:"partner"`
+	Token             string        `json:"token"`
+	TradeOfferMessage string        `json:"tradeoffermessage"`
+	Items             []V2OfferItem `json:"items"`
+}
+
+type APIV2TradeRequestGiveP2P struct {
+	Success bool      `json:"success"`
+	Offers  []V2Offer `json:"offers"`
+}
+
+type V2History struct {
+	ItemID         string `json:"item_id"`
+	MarketHashName string `json:"market_hash_name"`
+	Time           int64  `json:"time"`
+	Event          string `json:"event"`
+	App            string `json:"app"`
+	Stage          string `json:"stage"`
+	For            string `json:"for"`
+	CustomID       string `json:"custom_id"`
+	Paid           string `json:"paid"`
+	Received       string `json:"received"`
+	Currency       string `json:"currency"`
+	ClassID        string `json:"class"`
+	InstanceID     string `json:"instance"`
+}
+
+type APIV2History struct {
+	Success bool        `json:"success"`
+	Data    []V2History `json:"data"`
+}
+
+type APIV2MoneySend struct {
+	Success bool   `json:"success"`
+	Amount  int64  `json:"amount"`
+	ID      string `json:"id"`
+}
+
+type APIV2 struct {
+	api *API
+}
+
+type APIBuyByName struct {
+	ID         string
+	ClassID    string
+	InstanceID string
+	Hash       string
+	Price      int64
+}
+
+type APIAccountStatus struct {
+	Time            time.Time
+	Status          Status
+	Money           int64
+	InventoryStatus string
+	InventoryTime   string
+	Token           string
+	Orders          int
+	Trades          map[string]int // количество предметов по UIStatus
+}
+
+type APIInventoryRefresh struct {
+	Status   APIInventoryStatus
+	State    InventoryState
+	Started  time.Time
+	Duration time.Duration
+	Polls    int
+}
+
+type TradeURL struct {
+	Partner uint64
+	Token   string
+}
+
+type APITokenCheck struct {
+	Stored     string // токен, установленный на маркете до проверки
+	Configured string
+	UserToken  bool // Test().Status.UserToken до проверки
+	Mismatch   bool // Stored != Configured
+	Reset      bool // вызван SetToken
+}
+
+type ItemID struct {
+	ClassID    string
+	InstanceID string
+}
+
+type ItemInfoResult struct {
+	ID   ItemID
+	Info APIItemInfo
+	Err  error
 }
@@ -0,0 +1,135 @@
+package marketapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+//V2 - клиент для методов /api/v2/. Использует ключ, адрес и транспорт текущего API,
+//поэтому методы можно переносить на v2 по одному.
+func (a *API) V2() *APIV2 {
+	return &APIV2{api: a}
+}
+
+//Items - Список предметов, выставленных на продажу или ожидающих передачи.
+func (v *APIV2) Items() (APIV2Items, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2Items, v.api.URL, v.api.Key.Reveal()))
+	if err != nil {
+		return APIV2Items{}, err
+	}
+	var apiV2Items APIV2Items
+	json.Unmarshal(bytes, &apiV2Items)
+	return apiV2Items, nil
+}
+
+//AddToSale - Выставить предмет из инвентаря на продажу.
+//id - assetid предмета в инвентаре Steam.
+//price - цена в копейках(целое число) в валюте cur.
+func (v *APIV2) AddToSale(id string, price int64, cur string) (APIV2AddToSale, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2AddToSale, v.api.URL, v.api.Key.Reveal(), id, price, cur),
+		"asset_id", id, "price", price, "currency", cur)
+	if err != nil {
+		return APIV2AddToSale{}, err
+	}
+	var apiV2AddToSale APIV2AddToSale
+	json.Unmarshal(bytes, &apiV2AddToSale)
+	return apiV2AddToSale, nil
+}
+
+//SetPrice - Изменить цену предмета по item_id. Если price = 0, предмет будет снят с продажи.
+func (v *APIV2) SetPrice(itemid string, price int64, cur string) (APIV2SetPrice, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2SetPrice, v.api.URL, v.api.Key.Reveal(), itemid, price, cur),
+		"item_id", itemid, "price", price, "currency", cur)
+	if err != nil {
+		return APIV2SetPrice{}, err
+	}
+	var apiV2SetPrice APIV2SetPrice
+	json.Unmarshal(bytes, &apiV2SetPrice)
+	return apiV2SetPrice, nil
+}
+
+//Buy - Покупка предмета по market_hash_name.
+//price - максимальная цена в копейках(целое число), дороже которой предмет куплен не будет.
+func (v *APIV2) Buy(hashName string, price int64) (APIV2Buy, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2Buy, v.api.URL, v.api.Key.Reveal(), url.QueryEscape(hashName), price),
+		"market_hash_name", hashName, "price", price)
+	if err != nil {
+		return APIV2Buy{}, err
+	}
+	var apiV2Buy APIV2Buy
+	json.Unmarshal(bytes, &apiV2Buy)
+	return apiV2Buy, nil
+}
+
+//SearchItemByHashName - Список предложений о продаже предмета по market_hash_name.
+func (v *APIV2) SearchItemByHashName(hashName string) (APIV2SearchItemByHashName, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2SearchItemByHashName, v.api.URL, v.api.Key.Reveal(), url.QueryEscape(hashName)))
+	if err != nil {
+		return APIV2SearchItemByHashName{}, err
+	}
+	var apiV2SearchItemByHashName APIV2SearchItemByHashName
+	json.Unmarshal(bytes, &apiV2SearchItemByHashName)
+	return apiV2SearchItemByHashName, nil
+}
+
+//Prices - Минимальные цены на все предметы в валюте cur. Ключ не требуется.
+func (v *APIV2) Prices(cur string) (APIV2Prices, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2Prices, v.api.URL, cur))
+	if err != nil {
+		return APIV2Prices{}, err
+	}
+	var apiV2Prices APIV2Prices
+	json.Unmarshal(bytes, &apiV2Prices)
+	return apiV2Prices, nil
+}
+
+//PricesClassInstance - Минимальные цены в валюте cur с ключом classid_instanceid.
+func (v *APIV2) PricesClassInstance(cur string) (APIV2PricesClassInstance, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2PricesClassInstance, v.api.URL, cur))
+	if err != nil {
+		return APIV2PricesClassInstance{}, err
+	}
+	var apiV2PricesClassInstance APIV2PricesClassInstance
+	json.Unmarshal(bytes, &apiV2PricesClassInstance)
+	return apiV2PricesClassInstance, nil
+}
+
+//TradeRequestGiveP2P - Данные для отправки покупателю трейдов с проданными предметами.
+func (v *APIV2) TradeRequestGiveP2P() (APIV2TradeRequestGiveP2P, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2TradeRequestGiveP2P, v.api.URL, v.api.Key.Reveal()))
+	if err != nil {
+		return APIV2TradeRequestGiveP2P{}, err
+	}
+	var apiV2TradeRequestGiveP2P APIV2TradeRequestGiveP2P
+	json.Unmarshal(bytes, &apiV2TradeRequestGiveP2P)
+	return apiV2TradeRequestGiveP2P, nil
+}
+
+//History - История продаж и покупок за период startTime - endTime (unix time).
+func (v *APIV2) History(startTime int64, endTime int64) (APIV2History, error) {
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2History, v.api.URL, v.api.Key.Reveal(), startTime, endTime))
+	if err != nil {
+		return APIV2History{}, err
+	}
+	var apiV2History APIV2History
+	json.Unmarshal(bytes, &apiV2History)
+	return apiV2History, nil
+}
+
+//MoneySend - Перевод денег на другой аккаунт.
+//amount - сумма в копейках(целое число), не меньше 100.
+//whom - API ключ получателя, payPass - платежный пароль.
+func (v *APIV2) MoneySend(amount int64, whom string, payPass string) (APIV2MoneySend, error) {
+	if amount < 100 {
+		return APIV2MoneySend{}, errors.New(ErrAPIMinAmount)
+	}
+	bytes, err := v.api.makeGet(fmt.Sprintf(URLV2MoneySend, v.api.URL, amount, whom, url.QueryEscape(payPass), v.api.Key.Reveal()))
+	if err != nil {
+		return APIV2MoneySend{}, err
+	}
+	var apiV2MoneySend APIV2MoneySend
+	json.Unmarshal(bytes, &apiV2MoneySend)
+	return apiV2MoneySend, nil
+}